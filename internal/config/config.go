@@ -6,16 +6,18 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"fmt"
-	"reflect"
+	"sort"
+	"strings"
 )
 
 // fullConfigData represents all user instances parsed from user yaml
 type fullConfigData struct {
-	Instances map[string]Config `yaml:"instances"`
+	Variables map[string]interface{} `yaml:"variables"`
+	Instances map[string]Config      `yaml:"instances"`
 }
 
-// Get validates, parses user yaml data and returns config for given instance.
-func Get(dataYaml []byte, instance string) (*Config, error) {
+// parse validates and parses user yaml data.
+func parse(dataYaml []byte) (*fullConfigData, error) {
 	// Validates yaml config
 	err := validate.Validate(dataYaml)
 	if err != nil {
@@ -31,13 +33,56 @@ func Get(dataYaml []byte, instance string) (*Config, error) {
 		return nil, err
 	}
 
+	return &fullConfig, nil
+}
+
+// getInstanceNames returns sorted names of the instances.
+func getInstanceNames(instances map[string]Config) []string {
+	names := []string{}
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GetInstances validates, parses user yaml data and returns sorted names
+// of all instances.
+func GetInstances(dataYaml []byte) ([]string, error) {
+	fullConfig, err := parse(dataYaml)
+	if err != nil {
+		return nil, err
+	}
+
+	return getInstanceNames(fullConfig.Instances), nil
+}
+
+// Get validates, parses user yaml data and returns config for given instance.
+func Get(dataYaml []byte, instance string) (*Config, error) {
+	fullConfig, err := parse(dataYaml)
+	if err != nil {
+		return nil, err
+	}
+
 	// Gets config for given instance
-	config, ok := fullConfig.Instances[instance]
+	_, ok := fullConfig.Instances[instance]
 	if !ok {
-		availableInstances := reflect.ValueOf(fullConfig.Instances).MapKeys()
-		err := fmt.Errorf("There is no instance %q in %v", instance, availableInstances)
+		availableInstances := getInstanceNames(fullConfig.Instances)
+		err := fmt.Errorf("There is no instance %q in [%v]", instance,
+			strings.Join(availableInstances, ", "))
+		return nil, err
+	}
+
+	// Merges units of parent instances
+	config, err := resolveExtends(fullConfig.Instances, instance, nil)
+	if err != nil {
 		return nil, err
 	}
 
+	// Overrides global variables by instance ones
+	variables := mergeUnits(nil, fullConfig.Variables)
+	config.Variables = mergeUnits(variables, config.Variables)
+
 	return &config, nil
 }
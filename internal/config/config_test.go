@@ -35,7 +35,22 @@ func TestNonExistenInstanceConfig(t *testing.T) {
 
 	config, err := Get([]byte(data), "not-existen")
 	require.Nil(t, config)
-	require.Error(t, err)
+	require.ErrorContains(t, err, "[instance1, instance2]")
+}
+
+func TestGetInstances(t *testing.T) {
+	data := dedent.Dedent(`
+	  instances:
+	    instance2:
+	      commands:
+	    instance1:
+	      links:
+	`)
+	assertNoTab(data)
+
+	instances, err := GetInstances([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, []string{"instance1", "instance2"}, instances)
 }
 
 func TestChooseInstanceConfig(t *testing.T) {
@@ -64,6 +79,7 @@ func TestLinksConfig(t *testing.T) {
 	        link2:
 	          target: ./file2.txt
 	          link: ./link2
+	          backup: true
 	      commands:
 	      templates:
 	`)
@@ -77,11 +93,13 @@ func TestLinksConfig(t *testing.T) {
 	link1 := config.Links["link1"]
 	require.Equal(t, "./file1.txt", link1.TargetPath)
 	require.Equal(t, "./link1", link1.LinkPath)
+	require.False(t, link1.Backup)
 
 	require.Contains(t, config.Links, "link2")
 	link2 := config.Links["link2"]
 	require.Equal(t, "./file2.txt", link2.TargetPath)
 	require.Equal(t, "./link2", link2.LinkPath)
+	require.True(t, link2.Backup)
 }
 
 func TestCommandsConfig(t *testing.T) {
@@ -140,3 +158,191 @@ func TestTemplatesConfig(t *testing.T) {
 	require.Equal(t, "value1", templateData["variable1"].(string))
 	require.Equal(t, "value2", templateData["variable2"].(string))
 }
+
+func TestExtendsConfig(t *testing.T) {
+	t.Run("UnitsAreInheritedAndOverridden", func(t *testing.T) {
+		data := dedent.Dedent(`
+		  instances:
+		    base:
+		      links:
+		        link1:
+		          target: ./base1
+		          link: ./link1
+		        link2:
+		          target: ./base2
+		          link: ./link2
+		      commands:
+		        command1:
+		          input: ./input
+		          output: ./output
+		          command: cp {{.Input}} {{.Output}}
+		    desktop:
+		      links:
+		        link2:
+		          target: ./desktop2
+		          link: ./link2
+		    laptop:
+		      extends: [base, desktop]
+		      links:
+		        link3:
+		          target: ./laptop3
+		          link: ./link3
+		      templates:
+		        template1:
+		          input: ./input
+		          output: ./output
+		          data:
+		`)
+		assertNoTab(data)
+
+		config, err := Get([]byte(data), "laptop")
+		require.NoError(t, err)
+
+		require.Len(t, config.Links, 3)
+		require.Equal(t, "./base1", config.Links["link1"].TargetPath)
+		require.Equal(t, "./desktop2", config.Links["link2"].TargetPath)
+		require.Equal(t, "./laptop3", config.Links["link3"].TargetPath)
+		require.Contains(t, config.Commands, "command1")
+		require.Contains(t, config.Templates, "template1")
+	})
+
+	t.Run("OwnUnitsOverrideParentUnits", func(t *testing.T) {
+		data := dedent.Dedent(`
+		  instances:
+		    base:
+		      links:
+		        link1:
+		          target: ./base1
+		          link: ./link1
+		    work:
+		      extends: [base]
+		      links:
+		        link1:
+		          target: ./work1
+		          link: ./link1
+		`)
+		assertNoTab(data)
+
+		config, err := Get([]byte(data), "work")
+		require.NoError(t, err)
+		require.Equal(t, "./work1", config.Links["link1"].TargetPath)
+	})
+
+	t.Run("CycleIsDetected", func(t *testing.T) {
+		data := dedent.Dedent(`
+		  instances:
+		    a:
+		      extends: [b]
+		    b:
+		      extends: [c]
+		    c:
+		      extends: [a]
+		`)
+		assertNoTab(data)
+
+		config, err := Get([]byte(data), "a")
+		require.Nil(t, config)
+		require.ErrorContains(t, err, "a -> b -> c -> a")
+	})
+
+	t.Run("UnknownParent", func(t *testing.T) {
+		data := dedent.Dedent(`
+		  instances:
+		    a:
+		      extends: [b]
+		`)
+		assertNoTab(data)
+
+		config, err := Get([]byte(data), "a")
+		require.Nil(t, config)
+		require.ErrorContains(t, err, `unknown instance "b"`)
+	})
+}
+
+func TestVariablesConfig(t *testing.T) {
+	data := dedent.Dedent(`
+	  variables:
+	    editor: vim
+	    theme: dark
+	  instances:
+	    base:
+	      variables:
+	        font: mono
+	    laptop:
+	      extends: [base]
+	      variables:
+	        theme: light
+	`)
+	assertNoTab(data)
+
+	config, err := Get([]byte(data), "laptop")
+	require.NoError(t, err)
+
+	expectedVariables := map[string]interface{}{
+		"editor": "vim",
+		"theme":  "light",
+		"font":   "mono",
+	}
+	require.Equal(t, expectedVariables, config.Variables)
+}
+
+func TestSelectInstances(t *testing.T) {
+	data := dedent.Dedent(`
+	  instances:
+	    base:
+	      select:
+	        os: linux
+	    work:
+	      select:
+	        hostname: "work-*"
+	        user: alice
+	    ci:
+	      select:
+	        env: CI
+	    manual:
+	      links:
+	`)
+	assertNoTab(data)
+
+	environment := map[string]string{"CI": "true"}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := environment[name]
+		return value, ok
+	}
+
+	t.Run("SeveralInstancesMatch", func(t *testing.T) {
+		machine := Machine{
+			Hostname:  "work-12",
+			User:      "alice",
+			Os:        "linux",
+			LookupEnv: lookupEnv,
+		}
+
+		instances, err := SelectInstances([]byte(data), machine)
+		require.NoError(t, err)
+		require.Equal(t, []string{"base", "ci", "work"}, instances)
+	})
+
+	t.Run("AllFieldsHaveToMatch", func(t *testing.T) {
+		machine := Machine{
+			Hostname: "work-12",
+			User:     "bob",
+			Os:       "linux",
+		}
+
+		instances, err := SelectInstances([]byte(data), machine)
+		require.NoError(t, err)
+		require.Equal(t, []string{"base"}, instances)
+	})
+
+	t.Run("NothingMatches", func(t *testing.T) {
+		machine := Machine{
+			Hostname: "home",
+			Os:       "darwin",
+		}
+
+		instances, err := SelectInstances([]byte(data), machine)
+		require.Nil(t, instances)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mergeUnits copies units to the destination. Units with the same
+// names are overridden.
+func mergeUnits[Unit any](destination map[string]Unit,
+	units map[string]Unit) map[string]Unit {
+	if destination == nil {
+		destination = make(map[string]Unit)
+	}
+
+	for name, unit := range units {
+		destination[name] = unit
+	}
+
+	return destination
+}
+
+// resolveExtends returns the config of the instance with units that are
+// inherited from its parents. chain contains instances which are being
+// resolved and is used to detect inheritance cycles.
+func resolveExtends(instances map[string]Config, instance string,
+	chain []string) (Config, error) {
+	// Checks inheritance cycle
+	for _, chainInstance := range chain {
+		if chainInstance == instance {
+			cycle := strings.Join(append(chain, instance), " -> ")
+			return Config{}, fmt.Errorf("Instance inheritance cycle: %v",
+				cycle)
+		}
+	}
+	chain = append(chain, instance)
+
+	config := instances[instance]
+	if len(config.Extends) == 0 {
+		return config, nil
+	}
+
+	// Merges parent units in the given order
+	resolvedConfig := Config{
+		Extends: config.Extends,
+		Select:  config.Select,
+	}
+	for _, parent := range config.Extends {
+		if _, ok := instances[parent]; !ok {
+			return Config{}, fmt.Errorf("Instance %q extends unknown "+
+				"instance %q", instance, parent)
+		}
+
+		parentConfig, err := resolveExtends(instances, parent, chain)
+		if err != nil {
+			return Config{}, err
+		}
+
+		resolvedConfig.Variables = mergeUnits(resolvedConfig.Variables,
+			parentConfig.Variables)
+		resolvedConfig.Links = mergeUnits(resolvedConfig.Links,
+			parentConfig.Links)
+		resolvedConfig.Templates = mergeUnits(resolvedConfig.Templates,
+			parentConfig.Templates)
+		resolvedConfig.Commands = mergeUnits(resolvedConfig.Commands,
+			parentConfig.Commands)
+	}
+
+	// Overrides parent units by own ones
+	resolvedConfig.Variables = mergeUnits(resolvedConfig.Variables,
+		config.Variables)
+	resolvedConfig.Links = mergeUnits(resolvedConfig.Links, config.Links)
+	resolvedConfig.Templates = mergeUnits(resolvedConfig.Templates,
+		config.Templates)
+	resolvedConfig.Commands = mergeUnits(resolvedConfig.Commands,
+		config.Commands)
+
+	return resolvedConfig, nil
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeReader merges included config files into one config.
+type includeReader struct {
+	// locations are files where merged entries are defined. They are used
+	// to report duplicates.
+	locations map[string]string
+	merged    map[string]interface{}
+	// mergedFiles are resolved paths of merged files. A file can be
+	// reached several times, e.g. by overlapping globs.
+	mergedFiles map[string]bool
+}
+
+// Read reads the config file and merges all files from its include
+// list. Included paths are relative to the including file and can be
+// glob patterns. It returns yaml data that is ready to Get.
+func Read(configPath string) ([]byte, error) {
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checks that there is something to include. Invalid data is
+	// returned as is to be reported by Get.
+	config := struct {
+		Include interface{} `yaml:"include"`
+	}{}
+	err = yaml.Unmarshal(configData, &config)
+	if err != nil || config.Include == nil {
+		return configData, nil
+	}
+
+	reader := includeReader{
+		locations:   make(map[string]string),
+		merged:      make(map[string]interface{}),
+		mergedFiles: make(map[string]bool),
+	}
+	err = reader.mergeFile(configPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(reader.merged)
+}
+
+// getIncludedPaths returns paths of files which the include patterns
+// match. Patterns are relative to the directory.
+func getIncludedPaths(directory string, include interface{}) (
+	[]string, error) {
+	patterns, ok := include.([]interface{})
+	if !ok {
+		return nil, errors.New("include must be a list of paths")
+	}
+
+	includedPaths := []string{}
+	for _, pattern := range patterns {
+		patternString, ok := pattern.(string)
+		if !ok {
+			return nil, errors.New("include must be a list of paths")
+		}
+
+		if !path.IsAbs(patternString) {
+			patternString = path.Join(directory, patternString)
+		}
+
+		matches, err := filepath.Glob(patternString)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("included path %q doesn't exist",
+				patternString)
+		}
+
+		sort.Strings(matches)
+		includedPaths = append(includedPaths, matches...)
+	}
+
+	return includedPaths, nil
+}
+
+// mergeFile merges the file and its included files. chain contains
+// files which are being included and is used to detect include cycles.
+func (r *includeReader) mergeFile(filePath string, chain []string) error {
+	// Checks include cycle
+	for _, chainFile := range chain {
+		if chainFile == filePath {
+			cycle := strings.Join(append(chain, filePath), " -> ")
+			return fmt.Errorf("Include cycle: %v", cycle)
+		}
+	}
+	chain = append(chain, filePath)
+
+	// Skips the file if it's already merged
+	resolvedPath, err := filepath.EvalSymlinks(filepath.Clean(filePath))
+	if err != nil {
+		resolvedPath = filepath.Clean(filePath)
+	}
+	if r.mergedFiles[resolvedPath] {
+		return nil
+	}
+	r.mergedFiles[resolvedPath] = true
+
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	content := map[string]interface{}{}
+	err = yaml.Unmarshal(fileData, &content)
+	if err != nil {
+		return fmt.Errorf("%q: %w", filePath, err)
+	}
+	included := len(chain) > 1
+
+	// Merges the file content
+	for key, value := range content {
+		switch key {
+		case "include":
+			continue
+		case "instances":
+			err = r.mergeInstances(filePath, value, included)
+		case "variables":
+			err = r.mergeEntries(filePath, r.merged, key, value, key)
+		default:
+			// Other top level data is used only by the file itself
+			if _, exists := r.merged[key]; !exists {
+				r.merged[key] = value
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Merges included files
+	if content["include"] == nil {
+		return nil
+	}
+
+	includedPaths, err := getIncludedPaths(path.Dir(filePath),
+		content["include"])
+	if err != nil {
+		return fmt.Errorf("%q: %w", filePath, err)
+	}
+
+	for _, includedPath := range includedPaths {
+		err := r.mergeFile(includedPath, chain)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeInstances merges instances of the file. Relative unit paths of
+// included files are resolved against their directories.
+func (r *includeReader) mergeInstances(filePath string,
+	instances interface{}, included bool) error {
+	if instances == nil {
+		return nil
+	}
+
+	instancesMap, ok := instances.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%q: instances must be a dictionary", filePath)
+	}
+
+	mergedInstances, _ := r.merged["instances"].(map[string]interface{})
+	if mergedInstances == nil {
+		mergedInstances = make(map[string]interface{})
+		r.merged["instances"] = mergedInstances
+	}
+
+	for instanceName, instance := range instancesMap {
+		mergedInstance, _ :=
+			mergedInstances[instanceName].(map[string]interface{})
+		if mergedInstance == nil {
+			mergedInstance = make(map[string]interface{})
+			mergedInstances[instanceName] = mergedInstance
+		}
+
+		if instance == nil {
+			continue
+		}
+
+		instanceMap, ok := instance.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%q: instance %q must be a dictionary",
+				filePath, instanceName)
+		}
+
+		for field, value := range instanceMap {
+			location := "instances." + instanceName + "." + field
+
+			switch field {
+			case "variables", "links", "templates", "commands":
+				if included {
+					resolveUnitPaths(path.Dir(filePath), field, value)
+				}
+
+				err := r.mergeEntries(filePath, mergedInstance, field, value,
+					location)
+				if err != nil {
+					return err
+				}
+			default:
+				err := r.setLocation(filePath, location)
+				if err != nil {
+					return err
+				}
+				mergedInstance[field] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeEntries merges the dictionary value to the destination key.
+// Entries with the same names are reported as errors.
+func (r *includeReader) mergeEntries(filePath string,
+	destination map[string]interface{}, key string, value interface{},
+	location string) error {
+	if value == nil {
+		return nil
+	}
+
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%q: %v must be a dictionary", filePath, location)
+	}
+
+	mergedEntries, _ := destination[key].(map[string]interface{})
+	if mergedEntries == nil {
+		mergedEntries = make(map[string]interface{})
+		destination[key] = mergedEntries
+	}
+
+	for name, entry := range entries {
+		err := r.setLocation(filePath, location+"."+name)
+		if err != nil {
+			return err
+		}
+		mergedEntries[name] = entry
+	}
+
+	return nil
+}
+
+// setLocation records the file where the entry is defined. It returns
+// an error if the entry is already defined.
+func (r *includeReader) setLocation(filePath string, location string) error {
+	previousFilePath, exists := r.locations[location]
+	if exists {
+		return fmt.Errorf("Duplicate %q is defined in %q and %q", location,
+			previousFilePath, filePath)
+	}
+
+	r.locations[location] = filePath
+	return nil
+}
+
+// unitPathFields are fields of units which hold paths.
+var unitPathFields = map[string][]string{
+	"links":     {"target", "link"},
+	"templates": {"input", "output"},
+	"commands":  {"input", "output"},
+}
+
+// resolveUnitPaths makes relative unit paths of the included file
+// relative to its directory. Paths that start with a template or "~" are
+// left as is.
+func resolveUnitPaths(directory string, field string, units interface{}) {
+	unitsMap, _ := units.(map[string]interface{})
+	for _, unit := range unitsMap {
+		unitMap, _ := unit.(map[string]interface{})
+		for _, pathField := range unitPathFields[field] {
+			unitPath, _ := unitMap[pathField].(string)
+			if unitPath == "" || path.IsAbs(unitPath) ||
+				strings.HasPrefix(unitPath, "~") ||
+				strings.HasPrefix(unitPath, "{{") {
+				continue
+			}
+
+			unitMap[pathField] = path.Join(directory, unitPath)
+		}
+	}
+}
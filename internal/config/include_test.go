@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFiles writes the files to the directory. Names are relative
+// paths and values are dedented yaml data.
+func writeConfigFiles(directory string, files map[string]string) {
+	for name, data := range files {
+		data = dedent.Dedent(data)
+		assertNoTab(data)
+
+		filePath := path.Join(directory, name)
+		assertError(os.MkdirAll(path.Dir(filePath), 0755))
+		assertError(os.WriteFile(filePath, []byte(data), 0644))
+	}
+}
+
+func TestReadIncludes(t *testing.T) {
+	t.Run("FilesAreMerged", func(t *testing.T) {
+		directory := t.TempDir()
+		writeConfigFiles(directory, map[string]string{
+			"deploy-configs.yaml": `
+			  include: [modules/*.yaml]
+			  variables:
+			    editor: vim
+			  instances:
+			    home:
+			      links:
+			        link1:
+			          target: ./target1
+			          link: ./link1
+			`,
+			"modules/a.yaml": `
+			  variables:
+			    theme: dark
+			  instances:
+			    home:
+			      links:
+			        link2:
+			          target: ./target2
+			          link: ./link2
+			`,
+			"modules/b.yaml": `
+			  instances:
+			    home:
+			      commands:
+			        command1:
+			          input: ./input
+			          output: ./output
+			          command: cp {{.Input}} {{.Output}}
+			`,
+		})
+
+		data, err := Read(path.Join(directory, "deploy-configs.yaml"))
+		require.NoError(t, err)
+
+		config, err := Get(data, "home")
+		require.NoError(t, err)
+		require.Len(t, config.Links, 2)
+		require.Contains(t, config.Commands, "command1")
+		require.Equal(t, "dark", config.Variables["theme"])
+		require.Equal(t, "vim", config.Variables["editor"])
+	})
+
+	t.Run("DuplicateUnit", func(t *testing.T) {
+		directory := t.TempDir()
+		writeConfigFiles(directory, map[string]string{
+			"deploy-configs.yaml": `
+			  include: [a.yaml]
+			  instances:
+			    home:
+			      links:
+			        link1:
+			          target: ./target1
+			          link: ./link1
+			`,
+			"a.yaml": `
+			  instances:
+			    home:
+			      links:
+			        link1:
+			          target: ./target2
+			          link: ./link2
+			`,
+		})
+
+		_, err := Read(path.Join(directory, "deploy-configs.yaml"))
+		require.ErrorContains(t, err, `"instances.home.links.link1"`)
+		require.ErrorContains(t, err, path.Join(directory, "deploy-configs.yaml"))
+		require.ErrorContains(t, err, path.Join(directory, "a.yaml"))
+	})
+
+	t.Run("DiamondInclude", func(t *testing.T) {
+		directory := t.TempDir()
+		writeConfigFiles(directory, map[string]string{
+			"deploy-configs.yaml": `
+			  include: [a.yaml, b.yaml]
+			`,
+			"a.yaml": `
+			  include: [common.yaml]
+			`,
+			"b.yaml": `
+			  include: [./common.yaml]
+			`,
+			"common.yaml": `
+			  instances:
+			    home:
+			      links:
+			        link1:
+			          target: ./target1
+			          link: ./link1
+			`,
+		})
+
+		data, err := Read(path.Join(directory, "deploy-configs.yaml"))
+		require.NoError(t, err)
+
+		config, err := Get(data, "home")
+		require.NoError(t, err)
+		require.Len(t, config.Links, 1)
+	})
+
+	t.Run("OverlappingGlobs", func(t *testing.T) {
+		directory := t.TempDir()
+		writeConfigFiles(directory, map[string]string{
+			"deploy-configs.yaml": `
+			  include: [modules/*.yaml, modules/a.yaml]
+			`,
+			"modules/a.yaml": `
+			  variables:
+			    theme: dark
+			`,
+		})
+
+		data, err := Read(path.Join(directory, "deploy-configs.yaml"))
+		require.NoError(t, err)
+		require.Contains(t, string(data), "theme: dark")
+	})
+
+	t.Run("IncludeCycle", func(t *testing.T) {
+		directory := t.TempDir()
+		writeConfigFiles(directory, map[string]string{
+			"deploy-configs.yaml": `
+			  include: [a.yaml]
+			`,
+			"a.yaml": `
+			  include: [deploy-configs.yaml]
+			`,
+		})
+
+		_, err := Read(path.Join(directory, "deploy-configs.yaml"))
+		require.ErrorContains(t, err, "Include cycle")
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		directory := t.TempDir()
+		writeConfigFiles(directory, map[string]string{
+			"deploy-configs.yaml": `
+			  include: [missing.yaml]
+			`,
+		})
+
+		_, err := Read(path.Join(directory, "deploy-configs.yaml"))
+		require.ErrorContains(t, err, "doesn't exist")
+	})
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"path"
+	"sort"
+)
+
+// Machine describes the machine which instances are selected for.
+type Machine struct {
+	Hostname string
+	User     string
+	Os       string
+	// LookupEnv returns a value of the environment variable.
+	LookupEnv func(name string) (string, bool)
+}
+
+// matches checks that all specified fields of the selector match
+// the machine.
+func (s Selector) matches(machine Machine) bool {
+	if s.Hostname != "" {
+		matched, err := path.Match(s.Hostname, machine.Hostname)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if s.User != "" && s.User != machine.User {
+		return false
+	}
+
+	if s.Os != "" && s.Os != machine.Os {
+		return false
+	}
+
+	if s.Env != "" {
+		if machine.LookupEnv == nil {
+			return false
+		}
+		if _, ok := machine.LookupEnv(s.Env); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SelectInstances validates, parses user yaml data and returns sorted
+// names of instances which selectors match the machine. Instances
+// without selectors are never selected.
+func SelectInstances(dataYaml []byte, machine Machine) ([]string, error) {
+	fullConfig, err := parse(dataYaml)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []string{}
+	for name, instance := range fullConfig.Instances {
+		if instance.Select != nil && instance.Select.matches(machine) {
+			instances = append(instances, name)
+		}
+	}
+
+	if len(instances) == 0 {
+		return nil, errors.New("There is no instance that matches " +
+			"this machine")
+	}
+
+	sort.Strings(instances)
+	return instances, nil
+}
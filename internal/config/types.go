@@ -6,6 +6,10 @@ package config
 type Link struct {
 	TargetPath string `yaml:"target"`
 	LinkPath   string `yaml:"link"`
+	// Backup allows to move an occupying file away to create the link.
+	Backup bool `yaml:"backup"`
+	// When is a condition that enables the link.
+	When string `yaml:"when"`
 }
 
 // Command represents command from user config
@@ -13,6 +17,8 @@ type Command struct {
 	InputPath  string `yaml:"input"`
 	OutputPath string `yaml:"output"`
 	Command    string `yaml:"command"`
+	// When is a condition that enables the command.
+	When string `yaml:"when"`
 }
 
 // Command represents template from user config
@@ -20,11 +26,33 @@ type Template struct {
 	InputPath  string      `yaml:"input"`
 	OutputPath string      `yaml:"output"`
 	Data       interface{} `yaml:"data"`
+	// When is a condition that enables the template.
+	When string `yaml:"when"`
+}
+
+// Selector describes machines which the instance is deployed to
+// automatically. All specified fields have to match.
+type Selector struct {
+	// Hostname is a glob pattern of the machine hostname.
+	Hostname string `yaml:"hostname"`
+	User     string `yaml:"user"`
+	// Os is an operating system as runtime.GOOS names it.
+	Os string `yaml:"os"`
+	// Env is a name of an environment variable that has to be set.
+	Env string `yaml:"env"`
 }
 
 // Config represents parsed user config
 type Config struct {
-	Links     map[string]Link     `yaml:"links"`
-	Commands  map[string]Command  `yaml:"commands"`
-	Templates map[string]Template `yaml:"templates"`
+	// Extends are names of parent instances. Their units are inherited
+	// in the given order and can be overridden by name.
+	Extends []string `yaml:"extends"`
+	// Select allows to choose the instance without its name.
+	Select *Selector `yaml:"select"`
+	// Variables are user values that are available in paths, templates
+	// and commands as .Vars. They override the global variables.
+	Variables map[string]interface{} `yaml:"variables"`
+	Links     map[string]Link        `yaml:"links"`
+	Commands  map[string]Command     `yaml:"commands"`
+	Templates map[string]Template    `yaml:"templates"`
 }
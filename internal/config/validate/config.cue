@@ -3,6 +3,8 @@
 	[string]: {
 		target: string
 		link: string
+		backup?: bool
+		when?: string
 	}
 }
 
@@ -12,6 +14,7 @@
 		input:   string
 		output:  string
 		command: string
+		when?:   string
 	}
 }
 
@@ -21,11 +24,25 @@
 		input:  string
 		output: string
 		data:   _
+		when?:  string
 	}
 }
 
+// User values that are available as .Vars
+#Variables: {
+	[string]: _
+}
+
 // Instances of config
 #Instances: [string]: {
+	extends?:   [...string] | null
+	select?: {
+		hostname?: string
+		user?:     string
+		os?:       string
+		env?:      string
+	}
+	variables?: #Variables | null
 	links?:     #Links | null
 	commands?:  #Commands | null
 	templates?: #Templates | null
@@ -33,3 +50,6 @@
 
 // Top level dictionary of instances
 instances: #Instances
+
+// Top level variables that are shared between instances
+variables?: #Variables | null
@@ -8,26 +8,35 @@ import (
 	"github.com/backdround/deploy-configs/internal/config"
 	"github.com/backdround/deploy-configs/internal/deploy/commands"
 	"github.com/backdround/deploy-configs/internal/deploy/links"
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/internal/deploy/templates"
 	"github.com/backdround/deploy-configs/internal/pathexpander"
 	"github.com/backdround/go-indent"
 )
 
 type Logger interface {
-	Log(message string)
+	Skip(message string)
 	Fail(message string)
 }
 
 type dataConverter struct {
 	logger       Logger
 	pathExpander pathexpander.PathExpander
+	variables    map[string]interface{}
+	recorder     outcome.Recorder
 }
 
-func New(logger Logger,
-	pathExpander pathexpander.PathExpander) *dataConverter {
+// New creates new dataConverter. variables are passed to templates and
+// commands to be available as .Vars. Units which conditions are false
+// are recorded to the recorder as skipped. The recorder can be nil.
+func New(logger Logger, pathExpander pathexpander.PathExpander,
+	variables map[string]interface{},
+	recorder outcome.Recorder) *dataConverter {
 	return &dataConverter{
 		logger:       logger,
 		pathExpander: pathExpander,
+		variables:    variables,
+		recorder:     recorder,
 	}
 }
 
@@ -43,6 +52,43 @@ func (c dataConverter) pathExpand(unitName string, unitDescription string,
 	return expandedTemplate, err
 }
 
+// isEnabled evaluates the condition of the unit. Units without
+// a condition are enabled. Disabled units are logged and recorded as
+// skipped.
+func (c dataConverter) isEnabled(unitName string, kind outcome.Kind,
+	condition string) (bool, error) {
+	unitDescription := string(kind)
+
+	if condition == "" {
+		return true, nil
+	}
+
+	enabled, err := c.pathExpander.Evaluate(condition)
+	if err != nil {
+		condition = indent.Indent(condition, "  ", 1)
+		errorMessage := indent.Indent(err.Error(), "  ", 2)
+		err = fmt.Errorf("unable to evaluate %q %v condition:\n%v\n%v",
+			unitName, unitDescription, condition, errorMessage)
+		return false, err
+	}
+
+	if !enabled {
+		c.logger.Skip(fmt.Sprintf("%q %v is skipped, because its condition "+
+			"is false", unitName, unitDescription))
+
+		if c.recorder != nil {
+			c.recorder.Record(outcome.Outcome{
+				Kind:   kind,
+				Name:   unitName,
+				Action: outcome.Skipped,
+				Reason: "condition is false",
+			})
+		}
+	}
+
+	return enabled, nil
+}
+
 // RestructureLinks resturctures config links to deploy links
 func (c dataConverter) RestructureLinks(
 	configLinks map[string]config.Link) ([]links.Link, error) {
@@ -50,10 +96,19 @@ func (c dataConverter) RestructureLinks(
 	// Restructures config links to deploy links
 	newLinks := []links.Link{}
 	for linkName, link := range configLinks {
+		enabled, err := c.isEnabled(linkName, outcome.Link, link.When)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+
 		newStructuredLink := links.Link{
 			Name:       linkName,
 			TargetPath: link.TargetPath,
 			LinkPath:   link.LinkPath,
+			Backup:     link.Backup,
 		}
 		newLinks = append(newLinks, newStructuredLink)
 	}
@@ -85,11 +140,20 @@ func (c dataConverter) RestructureTemplates(
 	// Restructures config templates to deploy templates
 	newTemplates := []templates.Template{}
 	for templateName, template := range configTemplates {
+		enabled, err := c.isEnabled(templateName, outcome.Template, template.When)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+
 		newStructuredTemplate := templates.Template{
 			Name:       templateName,
 			InputPath:  template.InputPath,
 			OutputPath: template.OutputPath,
 			Data:       template.Data,
+			Variables:  c.variables,
 		}
 		newTemplates = append(newTemplates, newStructuredTemplate)
 	}
@@ -120,11 +184,20 @@ func (c dataConverter) RestructureCommands(
 	// Restructures config commands to deploy commands
 	newCommands := []commands.Command{}
 	for commandName, command := range configCommands {
+		enabled, err := c.isEnabled(commandName, outcome.Command, command.When)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+
 		newStructuredCommand := commands.Command{
 			Name:            commandName,
 			InputPath:       command.InputPath,
 			OutputPath:      command.OutputPath,
 			CommandTemplate: command.Command,
+			Variables:       c.variables,
 		}
 		newCommands = append(newCommands, newStructuredCommand)
 	}
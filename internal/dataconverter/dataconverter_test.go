@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/backdround/deploy-configs/internal/config"
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,7 +14,7 @@ import (
 // fakeLogger
 type fakeLogger struct{}
 
-func (l fakeLogger) Log(message string)  {}
+func (l fakeLogger) Skip(message string) {}
 func (l fakeLogger) Fail(message string) {}
 
 // //////////////////////////////////////////////////////////
@@ -25,6 +26,11 @@ func (e lenExpander) Expand(template string) (string, error) {
 	return fmt.Sprint(len(template)), nil
 }
 
+// Evaluate returns true only for "true" condition
+func (e lenExpander) Evaluate(condition string) (bool, error) {
+	return condition == "true", nil
+}
+
 // //////////////////////////////////////////////////////////
 // errorExpander
 type errorExpander struct{}
@@ -33,6 +39,10 @@ func (e errorExpander) Expand(template string) (string, error) {
 	return "", errors.New("something goes wrong")
 }
 
+func (e errorExpander) Evaluate(condition string) (bool, error) {
+	return false, errors.New("something goes wrong")
+}
+
 ////////////////////////////////////////////////////////////
 // link converting tests
 
@@ -42,11 +52,12 @@ func TestSuccessfulLinkConverting(t *testing.T) {
 		"l1": {
 			TargetPath: "ab",
 			LinkPath:   "abcd",
+			Backup:     true,
 		},
 	}
 
 	// Makes conversion
-	dataConverter := New(fakeLogger{}, lenExpander{})
+	dataConverter := New(fakeLogger{}, lenExpander{}, nil, nil)
 	deployLinks, err := dataConverter.RestructureLinks(configLinks)
 
 	// Asserts converted data
@@ -55,6 +66,7 @@ func TestSuccessfulLinkConverting(t *testing.T) {
 	require.Equal(t, "l1", deployLinks[0].Name)
 	require.Equal(t, "2", deployLinks[0].TargetPath)
 	require.Equal(t, "4", deployLinks[0].LinkPath)
+	require.True(t, deployLinks[0].Backup)
 }
 
 func TestFailedLinkConverting(t *testing.T) {
@@ -67,7 +79,7 @@ func TestFailedLinkConverting(t *testing.T) {
 	}
 
 	// Fails conversion
-	dataConverter := New(fakeLogger{}, errorExpander{})
+	dataConverter := New(fakeLogger{}, errorExpander{}, nil, nil)
 	deployLinks, err := dataConverter.RestructureLinks(configLinks)
 
 	// Asserts fail
@@ -75,6 +87,39 @@ func TestFailedLinkConverting(t *testing.T) {
 	require.Len(t, deployLinks, 0)
 }
 
+func TestConditionalLinkConverting(t *testing.T) {
+	// Creates data to convert
+	configLinks := map[string]config.Link{
+		"l1": {
+			TargetPath: "ab",
+			LinkPath:   "abcd",
+			When:       "true",
+		},
+		"l2": {
+			TargetPath: "ab",
+			LinkPath:   "abcd",
+			When:       "false",
+		},
+	}
+
+	// Makes conversion
+	disabled := &outcome.Collector{}
+	dataConverter := New(fakeLogger{}, lenExpander{}, nil, disabled)
+	deployLinks, err := dataConverter.RestructureLinks(configLinks)
+
+	// Asserts that only the enabled link is converted
+	require.NoError(t, err)
+	require.Len(t, deployLinks, 1)
+	require.Equal(t, "l1", deployLinks[0].Name)
+
+	// Asserts that the disabled link is recorded as skipped
+	require.Len(t, disabled.Outcomes, 1)
+	require.Equal(t, "l2", disabled.Outcomes[0].Name)
+	require.Equal(t, outcome.Skipped, disabled.Outcomes[0].Action)
+	require.Equal(t, outcome.Link, disabled.Outcomes[0].Kind)
+	require.NotEmpty(t, disabled.Outcomes[0].Reason)
+}
+
 ////////////////////////////////////////////////////////////
 // template converting tests
 
@@ -89,7 +134,7 @@ func TestSuccessfulTemplateConverting(t *testing.T) {
 	}
 
 	// Makes conversion
-	dataConverter := New(fakeLogger{}, lenExpander{})
+	dataConverter := New(fakeLogger{}, lenExpander{}, nil, nil)
 	deployTemplates, err := dataConverter.RestructureTemplates(configTemplates)
 
 	// Asserts converted data
@@ -112,7 +157,7 @@ func TestFailedTemplateConverting(t *testing.T) {
 	}
 
 	// Fails conversion
-	dataConverter := New(fakeLogger{}, errorExpander{})
+	dataConverter := New(fakeLogger{}, errorExpander{}, nil, nil)
 	deployTemplates, err := dataConverter.RestructureTemplates(configTemplates)
 
 	// Asserts fail
@@ -134,7 +179,7 @@ func TestSuccessfulCommandsConverting(t *testing.T) {
 	}
 
 	// Makes conversion
-	dataConverter := New(fakeLogger{}, lenExpander{})
+	dataConverter := New(fakeLogger{}, lenExpander{}, nil, nil)
 	deployCommands, err := dataConverter.RestructureCommands(configCommands)
 
 	// Asserts converted data
@@ -157,7 +202,7 @@ func TestFailedCommandsConverting(t *testing.T) {
 	}
 
 	// Fails conversion
-	dataConverter := New(fakeLogger{}, errorExpander{})
+	dataConverter := New(fakeLogger{}, errorExpander{}, nil, nil)
 	deployCommands, err := dataConverter.RestructureCommands(configCommands)
 
 	// Asserts fail
@@ -5,24 +5,30 @@ package commands
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
 	"sort"
+	"strings"
 	"text/template"
+	"time"
 
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/pkg/fsutility"
 	"github.com/backdround/go-indent"
 )
 
 type commandExecuter struct {
-	logger Logger
+	logger  Logger
+	options Options
 }
 
-func NewCommandExecuter(logger Logger) *commandExecuter {
+func NewCommandExecuter(logger Logger, options Options) *commandExecuter {
 	return &commandExecuter{
-		logger: logger,
+		logger:  logger,
+		options: options,
 	}
 }
 
@@ -50,91 +56,317 @@ func (e commandExecuter) logSuccess(command Command) {
 	e.logger.Success(message)
 }
 
-func (e commandExecuter) logSkip(command Command) {
+func (e commandExecuter) logSkip(command Command, reason string) {
 	message := fmt.Sprintf("Command %q is skipped", command.Name)
-	e.logger.Log(message)
+	if reason != "" {
+		message += ", because " + reason
+	}
+	e.logger.Skip(message)
 }
 
-// executeCommand expands command template, executes command,
-// checks that the OutputPath is created and logs all outcomes.
-func (e commandExecuter) executeCommand(c Command) (success bool) {
-	// Checks that the input file exists
-	inputPathType := fsutility.GetPathType(c.InputPath)
-	if inputPathType == fsutility.Notexisting {
-		e.logFail(c, "input file doesn't exist")
-		return false
+func (e commandExecuter) logExecution(command Command,
+	expandedCommand string) {
+	message := fmt.Sprintf("Command %q executes:\n%v",
+		command.Name, shift(expandedCommand, 1))
+	e.logger.Verbose(message)
+}
+
+func (e commandExecuter) logOutput(command Command, cmdOutput []byte) {
+	if len(cmdOutput) == 0 {
+		return
 	}
 
-	// Creates the output directory if it's needed
-	outputDirectory := path.Dir(c.OutputPath)
-	err := fsutility.MakeDirectoryIfDoesntExist(outputDirectory)
-	if err != nil {
-		e.logFail(c, err.Error())
-		return false
+	message := fmt.Sprintf("Command %q output:\n%v",
+		command.Name, shift(strings.TrimSuffix(string(cmdOutput), "\n"), 1))
+	e.logger.Debug(message)
+}
+
+func (e commandExecuter) logPlan(command Command, expandedCommand string) {
+	message := fmt.Sprintf("Command %q would execute:\n%v",
+		command.Name, shift("command: "+expandedCommand, 1))
+	e.logger.Success(message)
+}
+
+// report logs and records the outcome of the command execution.
+func (e commandExecuter) report(c Command, commandOutcome outcome.Outcome) {
+	switch {
+	case commandOutcome.Action == outcome.Failed:
+		e.logFail(c, commandOutcome.Error)
+	case commandOutcome.Action == outcome.Skipped:
+		e.logSkip(c, commandOutcome.Reason)
+	case e.options.DryRun:
+		e.logPlan(c, commandOutcome.Command)
+	default:
+		e.logSuccess(c)
 	}
 
-	// Saves a hash of the old output file (if it exists)
-	oldOutputFileHash := fsutility.GetFileHash(c.OutputPath)
+	if e.options.Recorder != nil {
+		e.options.Recorder.Record(commandOutcome)
+	}
+}
 
-	// Removes the old output file if it exists
-	outputPathType := fsutility.GetPathType(c.OutputPath)
-	if outputPathType != fsutility.Notexisting {
-		err := os.Remove(c.OutputPath)
-		if err != nil {
-			message := fmt.Sprintf("unable to replace output path:\n%v",
-				shift(err.Error(), 1))
-			e.logFail(c, message)
-			return false
-		}
+// newOutcome creates an outcome of the command execution.
+func newOutcome(c Command, action outcome.Action, hash []byte,
+	err error) outcome.Outcome {
+	commandOutcome := outcome.Outcome{
+		Kind:        outcome.Command,
+		Name:        c.Name,
+		Action:      action,
+		Source:      c.InputPath,
+		Destination: c.OutputPath,
+		Hash:        hash,
 	}
 
-	// Gets command template
+	if err != nil {
+		commandOutcome.Action = outcome.Failed
+		commandOutcome.Hash = nil
+		commandOutcome.Error = err.Error()
+	}
+
+	return commandOutcome
+}
+
+// expandCommand expands the command template of the given command.
+func expandCommand(c Command) (string, error) {
 	commandTemplate := template.New(c.Name).Option("missingkey=error")
-	commandTemplate, err = commandTemplate.Parse(c.CommandTemplate)
+	commandTemplate, err := commandTemplate.Parse(c.CommandTemplate)
 	if err != nil {
-		e.logFail(c, err.Error())
-		return false
+		return "", err
 	}
 
-	// Gets expanded command
-	expandData := map[string]string{
+	expandData := map[string]interface{}{
 		"Input":  c.InputPath,
 		"Output": c.OutputPath,
+		"Vars":   c.Variables,
 	}
 	expandedCommand := bytes.NewBuffer([]byte{})
 	err = commandTemplate.Execute(expandedCommand, expandData)
 	if err != nil {
-		e.logFail(c, err.Error())
-		return false
+		return "", err
 	}
 
-	// Executes the expanded command
-	cmd := exec.Command("sh", "-c", expandedCommand.String())
-	cmdOutput, err := cmd.Output()
+	return expandedCommand.String(), nil
+}
+
+// execute executes the expanded command and checks that it created
+// the outputPath. It returns stdout and stderr of the command.
+func execute(expandedCommand string, outputPath string) (
+	cmdOutput []byte, err error) {
+	cmd := exec.Command("sh", "-c", expandedCommand)
+	cmdOutput, err = cmd.CombinedOutput()
 	if err != nil {
-		os.Remove(c.OutputPath)
-		e.logFail(c, err.Error())
-		return false
+		os.Remove(outputPath)
+		return cmdOutput, err
 	}
 
 	// Checks that the command created the output file
-	outputPathType = fsutility.GetPathType(c.OutputPath)
+	outputPathType := fsutility.GetPathType(outputPath)
 	if outputPathType != fsutility.Regular {
 		message := fmt.Sprintf("command didn't create file. output:\n%v",
 			string(cmdOutput))
-		e.logFail(c, message)
+		return cmdOutput, errors.New(message)
+	}
+
+	return cmdOutput, nil
+}
+
+// Render executes the command into a temporary output file and returns
+// its data. The output path of the command isn't touched.
+func Render(c Command) ([]byte, error) {
+	// Checks that the input file exists
+	inputPathType := fsutility.GetPathType(c.InputPath)
+	if inputPathType == fsutility.Notexisting {
+		return nil, errors.New("input file doesn't exist")
+	}
+
+	// Redirects the command output to a temporary directory
+	temporaryDirectory, err := os.MkdirTemp("", "deploy-configs-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(temporaryDirectory)
+	c.OutputPath = path.Join(temporaryDirectory, path.Base(c.OutputPath))
+
+	// Executes the command
+	expandedCommand, err := expandCommand(c)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = execute(expandedCommand, c.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(c.OutputPath)
+}
+
+// confirmReplacing asks the confirmer whether the existing output can
+// be replaced with the new output of the command.
+func (e commandExecuter) confirmReplacing(c Command,
+	newOutputPath string) bool {
+	if e.options.Confirmer == nil {
+		return true
+	}
+
+	newData, err := os.ReadFile(newOutputPath)
+	if err != nil {
+		newData = nil
+	}
+
+	description := fmt.Sprintf("Command %q output is going to be "+
+		"replaced:\n%v", c.Name, shift(getDescription(c), 1))
+	return e.options.Confirmer.Confirm(description, c.OutputPath, newData)
+}
+
+// isPredictedToSkip checks that the output is left as it was deployed
+// and the input isn't changed after the output was made. Commands aren't
+// executed in dry runs, so a real run is only predicted to get the same
+// output and skip it.
+func (e commandExecuter) isPredictedToSkip(c Command) bool {
+	deployedHash, deployed := e.options.DeployedHashes[c.OutputPath]
+	if !deployed ||
+		!bytes.Equal(fsutility.GetFileHash(c.OutputPath), deployedHash) {
+		return false
+	}
+
+	inputInfo, err := os.Stat(c.InputPath)
+	if err != nil {
+		return false
+	}
+	outputInfo, err := os.Stat(c.OutputPath)
+	if err != nil {
 		return false
 	}
 
+	return !inputInfo.ModTime().After(outputInfo.ModTime())
+}
+
+// runCommand expands command template, executes command into
+// a temporary output, checks that it's created, moves it to the
+// OutputPath and returns what was done.
+func (e commandExecuter) runCommand(c Command) outcome.Outcome {
+	// Checks that the input file exists
+	inputPathType := fsutility.GetPathType(c.InputPath)
+	if inputPathType == fsutility.Notexisting {
+		err := errors.New("input file doesn't exist")
+		return newOutcome(c, outcome.Failed, nil, err)
+	}
+
+	// Gets expanded command
+	expandedCommand, err := expandCommand(c)
+	if err != nil {
+		return newOutcome(c, outcome.Failed, nil, err)
+	}
+
+	var createdDirectories []string
+	result := func(action outcome.Action, hash []byte,
+		err error) outcome.Outcome {
+		commandOutcome := newOutcome(c, action, hash, err)
+		commandOutcome.Command = expandedCommand
+		commandOutcome.CreatedDirectories = createdDirectories
+		return commandOutcome
+	}
+
+	// Protects the output file from overwriting local modifications
+	err = outcome.CheckOutputIsUnmodified(c.OutputPath,
+		e.options.DeployedHashes, e.options.Force)
+	if err != nil {
+		return result(outcome.Failed, nil, err)
+	}
+
+	// Checks the output directory without touching the filesystem
+	outputDirectory := path.Dir(c.OutputPath)
+	outputPathType := fsutility.GetPathType(c.OutputPath)
+	if e.options.DryRun {
+		err := fsutility.CheckDirectoryCanBeMade(outputDirectory)
+		if err != nil {
+			return result(outcome.Failed, nil, err)
+		}
+
+		if outputPathType == fsutility.Notexisting {
+			return result(outcome.Created, nil, nil)
+		}
+		if e.isPredictedToSkip(c) {
+			return result(outcome.Skipped, fsutility.GetFileHash(c.OutputPath),
+				nil)
+		}
+		return result(outcome.Replaced, nil, nil)
+	}
+
+	if outputPathType == fsutility.Directory {
+		return result(outcome.Failed, nil, errors.New(
+			"unable to replace output path:\n  output path is a directory"))
+	}
+
+	// Creates the output directory if it's needed
+	createdDirectories, err = fsutility.MakeDirectoryIfDoesntExist(
+		outputDirectory)
+	if err != nil {
+		return result(outcome.Failed, nil, err)
+	}
+
+	// Redirects the command output to a temporary directory next to the
+	// output path, so the old output is kept until the command succeeds
+	temporaryDirectory, err := os.MkdirTemp(outputDirectory,
+		".deploy-configs-*")
+	if err != nil {
+		return result(outcome.Failed, nil, err)
+	}
+	defer os.RemoveAll(temporaryDirectory)
+
+	temporaryCommand := c
+	temporaryCommand.OutputPath = path.Join(temporaryDirectory,
+		path.Base(c.OutputPath))
+	executedCommand, err := expandCommand(temporaryCommand)
+	if err != nil {
+		return result(outcome.Failed, nil, err)
+	}
+
+	// Executes the expanded command
+	e.logExecution(c, executedCommand)
+	cmdOutput, err := execute(executedCommand, temporaryCommand.OutputPath)
+	e.logOutput(c, cmdOutput)
+	if err != nil {
+		return result(outcome.Failed, nil, err)
+	}
+
 	// Checks that output file is changed
-	newOutputFileHash := fsutility.GetFileHash(c.OutputPath)
+	oldOutputFileHash := fsutility.GetFileHash(c.OutputPath)
+	newOutputFileHash := fsutility.GetFileHash(temporaryCommand.OutputPath)
 	if bytes.Equal(oldOutputFileHash, newOutputFileHash) {
-		e.logSkip(c)
-		return true
+		return result(outcome.Skipped, newOutputFileHash, nil)
+	}
+
+	// Asks before replacing the existing output
+	action := outcome.Created
+	if outputPathType != fsutility.Notexisting {
+		if !e.confirmReplacing(c, temporaryCommand.OutputPath) {
+			declined := result(outcome.Skipped, nil, nil)
+			declined.Reason = "replacing is declined"
+			return declined
+		}
+		action = outcome.Replaced
 	}
 
-	e.logSuccess(c)
-	return true
+	// Moves the new output to its place
+	err = os.Rename(temporaryCommand.OutputPath, c.OutputPath)
+	if err != nil {
+		message := fmt.Sprintf("unable to replace output path:\n%v",
+			shift(err.Error(), 1))
+		return result(outcome.Failed, nil, errors.New(message))
+	}
+
+	return result(action, newOutputFileHash, nil)
+}
+
+// executeCommand executes the command and logs the outcome.
+func (e commandExecuter) executeCommand(c Command) (success bool) {
+	started := time.Now()
+	commandOutcome := e.runCommand(c)
+	commandOutcome.Duration = time.Since(started)
+	e.report(c, commandOutcome)
+	return commandOutcome.Action != outcome.Failed
 }
 
 // ExecuteCommands expands and executes given commands
@@ -147,7 +379,9 @@ func (e commandExecuter) ExecuteCommands(commands []Command) (success bool) {
 	// Executes commands
 	success = true
 	for _, command := range commands {
-		success = success && e.executeCommand(command)
+		if !e.executeCommand(command) {
+			success = false
+		}
 	}
 	return success
 }
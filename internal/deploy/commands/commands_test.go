@@ -4,9 +4,12 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/pkg/fstestutility"
 	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,12 +34,12 @@ func TestSuccessfulExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Success", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 		// Asserts output file
 		outputPathType := fsutility.GetPathType(outputPath)
@@ -67,12 +70,12 @@ func TestSuccessfulExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Success", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 		// Asserts output file
 		outputPathType := fsutility.GetPathType(outputPath)
@@ -104,12 +107,12 @@ func TestSuccessfulExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Success", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 		// Asserts output file
 		outputPathType := fsutility.GetPathType(outputFile)
@@ -132,12 +135,12 @@ func TestFailedExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Fail", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 		// Asserts output file
 		outputPathType := fsutility.GetPathType(command.OutputPath)
@@ -159,12 +162,12 @@ func TestFailedExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Fail", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 		// Asserts output file
 		outputPathType := fsutility.GetPathType(command.OutputPath)
@@ -187,12 +190,12 @@ func TestFailedExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Fail", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 	})
 
 	t.Run("UnableToCreateOutputDirectoryDueToFileInPath", func(t *testing.T) {
@@ -218,12 +221,12 @@ func TestFailedExecuteCommand(t *testing.T) {
 		}
 
 		// Creates the logger mock
-		logger := &LoggerMock{}
+		logger := newLoggerMock()
 		defer logger.AssertExpectations(t)
 		logger.On("Fail", containsString("test-command")).Once()
 
 		// Executes the test
-		NewCommandExecuter(logger).executeCommand(command)
+		NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 		// Asserts that file in path wasn't changed
 		fileInPathType := fsutility.GetPathType(fileInPath)
@@ -255,12 +258,12 @@ func TestSkippedExecuteCommand(t *testing.T) {
 	}
 
 	// Creates the logger mock
-	logger := &LoggerMock{}
+	logger := newLoggerMock()
 	defer logger.AssertExpectations(t)
-	logger.On("Log", containsString("test-command")).Once()
+	logger.On("Skip", containsString("test-command")).Once()
 
 	// Executes the test
-	NewCommandExecuter(logger).executeCommand(command)
+	NewCommandExecuter(logger, Options{}).executeCommand(command)
 
 	// Asserts output file
 	outputPathType := fsutility.GetPathType(outputFile)
@@ -270,3 +273,255 @@ func TestSkippedExecuteCommand(t *testing.T) {
 	fstestutility.AssertNoError(err)
 	require.Equal(t, inputFileData, string(outputFileData))
 }
+
+func TestDryRunExecuteCommand(t *testing.T) {
+	t.Run("OutputFileExists", func(t *testing.T) {
+		// Creates input file
+		inputFile, cleanup := fstestutility.
+			CreateTemporaryFileWithData("some data")
+		defer cleanup()
+
+		// Creates output file
+		outputFile, cleanup := fstestutility.
+			CreateTemporaryFileWithData("old data")
+		defer cleanup()
+
+		// Creates test data
+		command := Command{
+			Name:            "test-command",
+			InputPath:       inputFile,
+			OutputPath:      outputFile,
+			CommandTemplate: "cat {{.Input}} > {{.Output}}",
+		}
+
+		// Creates the logger mock
+		expandedCommand := "cat " + inputFile + " > " + outputFile
+		logger := newLoggerMock()
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString(expandedCommand)).Once()
+
+		// Executes the test
+		options := Options{DryRun: true}
+		NewCommandExecuter(logger, options).executeCommand(command)
+
+		// Asserts that the output file wasn't changed
+		outputFileData, err := os.ReadFile(outputFile)
+		fstestutility.AssertNoError(err)
+		require.Equal(t, "old data", string(outputFileData))
+	})
+
+	t.Run("DeployedOutputIsPredictedToSkip", func(t *testing.T) {
+		// Creates input file
+		inputFile, cleanup := fstestutility.
+			CreateTemporaryFileWithData("some data")
+		defer cleanup()
+
+		// Creates output file after the input
+		outputFile, cleanup := fstestutility.
+			CreateTemporaryFileWithData("some data")
+		defer cleanup()
+		past := time.Now().Add(-time.Hour)
+		fstestutility.AssertNoError(os.Chtimes(inputFile, past, past))
+
+		command := Command{
+			Name:            "test-command",
+			InputPath:       inputFile,
+			OutputPath:      outputFile,
+			CommandTemplate: "cat {{.Input}} > {{.Output}}",
+		}
+		deployedHashes := map[string][]byte{
+			outputFile: fsutility.GetHash([]byte("some data")),
+		}
+
+		// Creates the logger mock
+		logger := newLoggerMock()
+		defer logger.AssertExpectations(t)
+		logger.On("Skip", containsString("test-command")).Once()
+
+		// Executes the test
+		collector := &outcome.Collector{}
+		options := Options{
+			DryRun:         true,
+			Recorder:       collector,
+			DeployedHashes: deployedHashes,
+		}
+		NewCommandExecuter(logger, options).executeCommand(command)
+		require.Equal(t, outcome.Skipped, collector.Outcomes[0].Action)
+
+		// Predicts replacing if the input is changed after deploying
+		future := time.Now().Add(time.Hour)
+		fstestutility.AssertNoError(os.Chtimes(inputFile, future, future))
+		logger.On("Success", containsString("would execute")).Once()
+		NewCommandExecuter(logger, options).executeCommand(command)
+		require.Equal(t, outcome.Replaced, collector.Outcomes[1].Action)
+	})
+
+	t.Run("UnableToCreateOutputDirectory", func(t *testing.T) {
+		// Creates input file
+		inputFile, cleanup := fstestutility.
+			CreateTemporaryFileWithData("some data")
+		defer cleanup()
+
+		// Creates output path
+		fileInPath, cleanup := fstestutility.CreateTemporaryFileWithData("")
+		defer cleanup()
+
+		// Creates test data
+		command := Command{
+			Name:            "test-command",
+			InputPath:       inputFile,
+			OutputPath:      path.Join(fileInPath, "out.txt"),
+			CommandTemplate: "cat {{.Input}} > {{.Output}}",
+		}
+
+		// Creates the logger mock
+		logger := newLoggerMock()
+		defer logger.AssertExpectations(t)
+		logger.On("Fail", containsString("test-command")).Once()
+
+		// Executes the test
+		options := Options{DryRun: true}
+		NewCommandExecuter(logger, options).executeCommand(command)
+	})
+}
+
+func TestRecordedOutcome(t *testing.T) {
+	// Creates input file
+	inputFile, cleanup := fstestutility.
+		CreateTemporaryFileWithData("some data")
+	defer cleanup()
+
+	// Creates output path
+	outputPath := fstestutility.GetAvailableTempPath()
+	defer os.Remove(outputPath)
+
+	// Creates test data
+	command := Command{
+		Name:            "test-command",
+		InputPath:       inputFile,
+		OutputPath:      outputPath,
+		CommandTemplate: "cat {{.Input}} > {{.Output}}",
+	}
+
+	// Creates the logger mock
+	logger := newLoggerMock()
+	logger.On("Success", containsString("test-command")).Once()
+
+	// Executes the test
+	collector := &outcome.Collector{}
+	options := Options{Recorder: collector}
+	NewCommandExecuter(logger, options).executeCommand(command)
+
+	// Asserts the recorded outcome
+	require.Len(t, collector.Outcomes, 1)
+	recordedOutcome := collector.Outcomes[0]
+	require.Equal(t, outcome.Command, recordedOutcome.Kind)
+	require.Equal(t, outcome.Created, recordedOutcome.Action)
+	require.Equal(t, outputPath, recordedOutcome.Destination)
+	require.Equal(t, fsutility.GetHash([]byte("some data")),
+		recordedOutcome.Hash)
+}
+
+func TestModifiedOutputExecuteCommand(t *testing.T) {
+	// Creates input file
+	inputFile, cleanup := fstestutility.
+		CreateTemporaryFileWithData("some data")
+	defer cleanup()
+
+	// Creates a modified output file
+	outputFile, cleanup := fstestutility.
+		CreateTemporaryFileWithData("modified data")
+	defer cleanup()
+
+	// Creates test data
+	command := Command{
+		Name:            "test-command",
+		InputPath:       inputFile,
+		OutputPath:      outputFile,
+		CommandTemplate: "cat {{.Input}} > {{.Output}}",
+	}
+	options := Options{
+		DeployedHashes: map[string][]byte{
+			outputFile: fsutility.GetHash([]byte("deployed data")),
+		},
+	}
+
+	// Creates the logger mock
+	logger := newLoggerMock()
+	defer logger.AssertExpectations(t)
+	logger.On("Fail", containsString("modified after deploying")).Once()
+
+	// Executes the test
+	NewCommandExecuter(logger, options).executeCommand(command)
+
+	// Asserts that the output file wasn't removed
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "modified data", string(data))
+}
+
+func TestConfirmedExecuteCommand(t *testing.T) {
+	prepare := func(t *testing.T) Command {
+		inputFile, cleanup := fstestutility.
+			CreateTemporaryFileWithData("new data")
+		t.Cleanup(cleanup)
+
+		// Creates an existing output file
+		outputPath, outputCleanup := fstestutility.
+			CreateTemporaryFileWithData("old data")
+		t.Cleanup(outputCleanup)
+
+		return Command{
+			Name:            "test-command",
+			InputPath:       inputFile,
+			OutputPath:      outputPath,
+			CommandTemplate: "cat {{.Input}} > {{.Output}}",
+		}
+	}
+
+	t.Run("Confirmed", func(t *testing.T) {
+		command := prepare(t)
+
+		confirmer := &ConfirmerMock{}
+		defer confirmer.AssertExpectations(t)
+		confirmer.On("Confirm", containsString("test-command"),
+			command.OutputPath, []byte("new data")).Return(true).Once()
+
+		logger := newLoggerMock()
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("test-command")).Once()
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewCommandExecuter(logger, options).executeCommand(command)
+
+		// Asserts that the output file is replaced
+		require.True(t, success)
+		data, err := os.ReadFile(command.OutputPath)
+		require.NoError(t, err)
+		require.Equal(t, "new data", string(data))
+	})
+
+	t.Run("Declined", func(t *testing.T) {
+		command := prepare(t)
+
+		confirmer := &ConfirmerMock{}
+		defer confirmer.AssertExpectations(t)
+		confirmer.On("Confirm", mock.Anything, mock.Anything, mock.Anything).
+			Return(false).Once()
+
+		logger := newLoggerMock()
+		defer logger.AssertExpectations(t)
+		logger.On("Skip", containsString("replacing is declined")).Once()
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewCommandExecuter(logger, options).executeCommand(command)
+
+		// Asserts that the output file is left
+		require.True(t, success)
+		data, err := os.ReadFile(command.OutputPath)
+		require.NoError(t, err)
+		require.Equal(t, "old data", string(data))
+	})
+}
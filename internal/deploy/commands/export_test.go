@@ -25,6 +25,40 @@ func (l *LoggerMock) Log(message string) {
 	l.Called(message)
 }
 
+func (l *LoggerMock) Skip(message string) {
+	l.Called(message)
+}
+
+func (l *LoggerMock) Verbose(message string) {
+	l.Called(message)
+}
+
+func (l *LoggerMock) Debug(message string) {
+	l.Called(message)
+}
+
+// newLoggerMock returns a LoggerMock that allows messages about
+// executions and outputs of commands.
+func newLoggerMock() *LoggerMock {
+	logger := &LoggerMock{}
+	logger.On("Verbose", containsString("executes")).Maybe()
+	logger.On("Debug", containsString("output")).Maybe()
+	return logger
+}
+
+////////////////////////////////////////////////////////////
+// ConfirmerMock
+
+type ConfirmerMock struct {
+	mock.Mock
+}
+
+func (c *ConfirmerMock) Confirm(description string, outputPath string,
+	newData []byte) bool {
+	args := c.Called(description, outputPath, newData)
+	return args.Bool(0)
+}
+
 ////////////////////////////////////////////////////////////
 // Utility functions
 
@@ -1,5 +1,7 @@
 package commands
 
+import "github.com/backdround/deploy-configs/internal/deploy/outcome"
+
 // Command represents command that creates OutputPath from
 // InputPath by this package
 type Command struct {
@@ -7,10 +9,39 @@ type Command struct {
 	InputPath       string
 	OutputPath      string
 	CommandTemplate string
+	// Variables are available in CommandTemplate as .Vars.
+	Variables map[string]interface{}
+}
+
+// Options adjusts the behaviour of commandExecuter.
+type Options struct {
+	// DryRun makes commandExecuter only log commands that would be
+	// executed without touching the filesystem. Commands which outputs
+	// are left as deployed and which inputs are older than the outputs
+	// are predicted to be skipped.
+	DryRun bool
+
+	// Recorder receives outcomes of all executed commands. It's optional.
+	Recorder outcome.Recorder
+
+	// Confirmer is asked before replacing an existing output with a new
+	// one. It's optional.
+	Confirmer outcome.Confirmer
+
+	// DeployedHashes are hashes of outputs by their paths which were
+	// deployed last time. commandExecuter refuses to overwrite outputs
+	// which were modified after deploying. It's optional.
+	DeployedHashes map[string][]byte
+
+	// Force allows to overwrite modified outputs.
+	Force bool
 }
 
 type Logger interface {
 	Success(message string)
 	Fail(message string)
 	Log(message string)
+	Skip(message string)
+	Verbose(message string)
+	Debug(message string)
 }
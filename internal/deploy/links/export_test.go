@@ -25,14 +25,45 @@ func (l *LoggerMock) Log(message string) {
 	l.Called(message)
 }
 
+func (l *LoggerMock) Skip(message string) {
+	l.Called(message)
+}
+
 func getLoggerDummy() Logger {
 	logger := &LoggerMock{}
 	logger.On("Success", mock.Anything).Maybe()
 	logger.On("Fail", mock.Anything).Maybe()
 	logger.On("Log", mock.Anything).Maybe()
+	logger.On("Skip", mock.Anything).Maybe()
 	return logger
 }
 
+////////////////////////////////////////////////////////////
+// ConfirmerMock
+
+type ConfirmerMock struct {
+	mock.Mock
+}
+
+func (c *ConfirmerMock) Confirm(description string, outputPath string,
+	newData []byte) bool {
+	args := c.Called(description, outputPath, newData)
+	return args.Bool(0)
+}
+
+////////////////////////////////////////////////////////////
+// BackupRecorderMock
+
+type BackupRecorderMock struct {
+	mock.Mock
+}
+
+func (r *BackupRecorderMock) RecordBackup(name string, originalPath string,
+	backupPath string) error {
+	args := r.Called(name, originalPath, backupPath)
+	return args.Error(0)
+}
+
 ////////////////////////////////////////////////////////////
 // Utility functions
 
@@ -3,24 +3,29 @@
 package links
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"sort"
+	"time"
 
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/pkg/fsutility"
 	"github.com/backdround/go-indent"
 )
 
 // linkMaker makes link and logs all outcomes.
 type linkMaker struct {
-	logger Logger
+	logger  Logger
+	options Options
 }
 
-func NewLinkMaker(logger Logger) linkMaker {
+func NewLinkMaker(logger Logger, options Options) linkMaker {
 	return linkMaker{
-		logger: logger,
+		logger:  logger,
+		options: options,
 	}
 }
 
@@ -42,31 +47,208 @@ func (m linkMaker) logFail(link Link, reason string) {
 	m.logger.Fail(message)
 }
 
-func (m linkMaker) logSuccess(link Link) {
+// getBackupDescription returns the link description with the backup
+// path if it's set.
+func getBackupDescription(link Link, backupPath string) string {
+	description := getDescription(link)
+	if backupPath != "" {
+		description += fmt.Sprintf("\nbackup: %q", backupPath)
+	}
+	return description
+}
+
+func (m linkMaker) logSuccess(link Link, backupPath string) {
 	message := fmt.Sprintf("Link %q created:\n%v", link.Name,
-		shift(getDescription(link), 1))
+		shift(getBackupDescription(link, backupPath), 1))
 	m.logger.Success(message)
 }
 
-func (m linkMaker) logSkip(link Link) {
+func (m linkMaker) logSkip(link Link, reason string) {
 	message := fmt.Sprintf("Link %q is skipped", link.Name)
-	m.logger.Log(message)
+	if reason != "" {
+		message += ", because " + reason
+	}
+	m.logger.Skip(message)
 }
 
-func (m linkMaker) makeLink(link Link) (success bool) {
+func (m linkMaker) logPlan(link Link, action outcome.Action,
+	backupPath string) {
+	message := fmt.Sprintf("Link %q would be %v:\n%v", link.Name, action,
+		shift(getBackupDescription(link, backupPath), 1))
+	m.logger.Success(message)
+}
+
+// report logs and records the outcome of the link deploying.
+func (m linkMaker) report(link Link, linkOutcome outcome.Outcome) {
+	switch {
+	case linkOutcome.Action == outcome.Failed:
+		m.logFail(link, linkOutcome.Error)
+	case linkOutcome.Action == outcome.Skipped:
+		m.logSkip(link, linkOutcome.Reason)
+	case m.options.DryRun:
+		m.logPlan(link, linkOutcome.Action, linkOutcome.Backup)
+	default:
+		m.logSuccess(link, linkOutcome.Backup)
+	}
+
+	if m.options.Recorder != nil {
+		m.options.Recorder.Record(linkOutcome)
+	}
+}
+
+// newOutcome creates an outcome of the link deploying.
+func newOutcome(link Link, action outcome.Action, err error) outcome.Outcome {
+	linkOutcome := outcome.Outcome{
+		Kind:        outcome.Link,
+		Name:        link.Name,
+		Action:      action,
+		Source:      link.TargetPath,
+		Destination: link.LinkPath,
+	}
+
+	if err != nil {
+		linkOutcome.Action = outcome.Failed
+		linkOutcome.Error = err.Error()
+	}
+
+	return linkOutcome
+}
+
+// shouldBackup checks that a file which occupies the link path is
+// allowed to be moved to the backup directory.
+func (m linkMaker) shouldBackup(link Link) bool {
+	return link.Backup || m.options.Backup
+}
+
+// getBackupPath returns a path where a file that occupies the link
+// path is moved to.
+func (m linkMaker) getBackupPath(link Link) (string, error) {
+	if m.options.BackupDirectory == "" {
+		return "", errors.New("backup directory isn't specified")
+	}
+
+	backupPath := path.Join(m.options.BackupDirectory, link.LinkPath)
+	if fsutility.GetPathType(backupPath) != fsutility.Notexisting {
+		return "", fmt.Errorf("backup path is occupied: %q", backupPath)
+	}
+
+	return backupPath, nil
+}
+
+// backupOccupant moves the file which occupies the link path to
+// the backup directory and records the backup. If the backup can't be
+// recorded, the file is moved back. It returns the backup path.
+func (m linkMaker) backupOccupant(link Link) (string, error) {
+	backupPath, err := m.getBackupPath(link)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = fsutility.MakeDirectoryIfDoesntExist(path.Dir(backupPath))
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(link.LinkPath, backupPath)
+	if err != nil {
+		return "", err
+	}
+
+	if m.options.BackupRecorder != nil {
+		err = m.options.BackupRecorder.RecordBackup(link.Name, link.LinkPath,
+			backupPath)
+		if err != nil {
+			os.Rename(backupPath, link.LinkPath)
+			return "", fmt.Errorf("unable to record backup: %w", err)
+		}
+	}
+
+	return backupPath, nil
+}
+
+// planLink returns what deployLink would do with the link without
+// touching the filesystem.
+func (m linkMaker) planLink(link Link) (outcome.Action, error) {
+	// Checks the link directory
+	err := fsutility.CheckDirectoryCanBeMade(path.Dir(link.LinkPath))
+	if err != nil {
+		return outcome.Failed, err
+	}
+
+	switch fsutility.GetPathType(link.LinkPath) {
+	case fsutility.Notexisting:
+		return outcome.Created, nil
+	case fsutility.Symlink:
+		if fsutility.IsLinkPointsToDestination(link.LinkPath, link.TargetPath) {
+			return outcome.Skipped, nil
+		}
+		return outcome.Replaced, nil
+	}
+
+	if m.shouldBackup(link) {
+		_, err := m.getBackupPath(link)
+		if err != nil {
+			return outcome.Failed, err
+		}
+		return outcome.Replaced, nil
+	}
+
+	return outcome.Failed, errors.New("link path is occupied")
+}
+
+// confirmReplacing asks the confirmer whether the link which points
+// elsewhere can be replaced.
+func (m linkMaker) confirmReplacing(link Link) bool {
+	if m.options.Confirmer == nil {
+		return true
+	}
+
+	currentTarget, _ := os.Readlink(link.LinkPath)
+	description := fmt.Sprintf("Link %q is going to be replaced:\n%v",
+		link.Name, shift(fmt.Sprintf("link: %q\ncurrent target: %q\n"+
+			"new target: %q", link.LinkPath, currentTarget, link.TargetPath), 1))
+	return m.options.Confirmer.Confirm(description, link.LinkPath, nil)
+}
+
+// isOccupied checks that the link path is occupied by a file
+// that isn't a symlink.
+func isOccupied(link Link) bool {
+	linkType := fsutility.GetPathType(link.LinkPath)
+	return linkType != fsutility.Notexisting && linkType != fsutility.Symlink
+}
+
+// deployLink creates the link and returns what was done.
+func (m linkMaker) deployLink(link Link) outcome.Outcome {
 	// Checks the target path
 	targetType := fsutility.GetPathType(link.TargetPath)
 	if targetType == fsutility.Notexisting {
-		m.logFail(link, "target path isn't exist")
-		return false
+		err := errors.New("target path isn't exist")
+		return newOutcome(link, outcome.Failed, err)
+	}
+
+	if m.options.DryRun {
+		action, err := m.planLink(link)
+		linkOutcome := newOutcome(link, action, err)
+		if isOccupied(link) && linkOutcome.Action == outcome.Replaced {
+			linkOutcome.Backup, _ = m.getBackupPath(link)
+		}
+		return linkOutcome
 	}
 
 	// Creates the link directory
 	linkDirectory := path.Dir(link.LinkPath)
-	err := fsutility.MakeDirectoryIfDoesntExist(linkDirectory)
+	createdDirectories, err := fsutility.MakeDirectoryIfDoesntExist(
+		linkDirectory)
 	if err != nil {
-		m.logFail(link, err.Error())
-		return false
+		return newOutcome(link, outcome.Failed, err)
+	}
+
+	backupPath := ""
+	result := func(action outcome.Action, err error) outcome.Outcome {
+		linkOutcome := newOutcome(link, action, err)
+		linkOutcome.CreatedDirectories = createdDirectories
+		linkOutcome.Backup = backupPath
+		return linkOutcome
 	}
 
 	linkType := fsutility.GetPathType(link.LinkPath)
@@ -76,19 +258,35 @@ func (m linkMaker) makeLink(link Link) (success bool) {
 		skip := fsutility.IsLinkPointsToDestination(link.LinkPath,
 			link.TargetPath)
 		if skip {
-			m.logSkip(link)
-			return true
+			return result(outcome.Skipped, nil)
 		}
 	}
 
 	// Checks the link to replace
+	action := outcome.Created
 	if linkType == fsutility.Symlink {
+		if !m.confirmReplacing(link) {
+			declined := result(outcome.Skipped, nil)
+			declined.Reason = "replacing is declined"
+			return declined
+		}
+
 		err := os.Remove(link.LinkPath)
 		if err != nil {
 			message := "unable to replace link:\n  " + err.Error()
-			m.logFail(link, message)
-			return false
+			return result(outcome.Failed, errors.New(message))
 		}
+		action = outcome.Replaced
+	}
+
+	// Moves the occupying file to the backup directory
+	if isOccupied(link) && m.shouldBackup(link) {
+		backupPath, err = m.backupOccupant(link)
+		if err != nil {
+			message := "unable to backup occupying file:\n  " + err.Error()
+			return result(outcome.Failed, errors.New(message))
+		}
+		action = outcome.Replaced
 	}
 
 	// Creates the link
@@ -97,16 +295,74 @@ func (m linkMaker) makeLink(link Link) (success bool) {
 		err = os.Symlink(link.TargetPath, link.LinkPath)
 		if err != nil {
 			message := "unable to create link:\n  " + err.Error()
-			m.logFail(link, message)
-			return false
+			return result(outcome.Failed, errors.New(message))
 		}
 
-		m.logSuccess(link)
-		return true
+		return result(action, nil)
+	}
+
+	return result(outcome.Failed, errors.New("link path is occupied"))
+}
+
+// makeLink creates the link and logs the outcome.
+func (m linkMaker) makeLink(link Link) (success bool) {
+	started := time.Now()
+	linkOutcome := m.deployLink(link)
+	linkOutcome.Duration = time.Since(started)
+	m.report(link, linkOutcome)
+	return linkOutcome.Action != outcome.Failed
+}
+
+// expandLink returns the link itself if its target isn't a directory.
+// Otherwise it returns links for all entries in the target directory.
+func expandLink(link Link) ([]Link, error) {
+	targetType := fsutility.GetPathType(link.TargetPath)
+	if targetType != fsutility.Directory {
+		return []Link{link}, nil
+	}
+
+	// Reads all entries in the target directory
+	entryInfos, err := ioutil.ReadDir(link.TargetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Makes a link for every entry in the target directory
+	specificLinks := []Link{}
+	for _, entryInfo := range entryInfos {
+		targetFileName := path.Base(entryInfo.Name())
+
+		specificLink := Link{
+			Name:       link.Name + "/" + targetFileName,
+			TargetPath: path.Join(link.TargetPath, targetFileName),
+			LinkPath:   path.Join(link.LinkPath, targetFileName),
+			Backup:     link.Backup,
+		}
+		specificLinks = append(specificLinks, specificLink)
+	}
+
+	return specificLinks, nil
+}
+
+// ExpandLinks returns links as CreateLinks creates them: links
+// which targets are directories are replaced with links for all
+// entries in that directories.
+func ExpandLinks(links []Link) ([]Link, error) {
+	expandedLinks := []Link{}
+	for _, link := range links {
+		specificLinks, err := expandLink(link)
+		if err != nil {
+			return nil, fmt.Errorf("unable to expand %q link: %w",
+				link.Name, err)
+		}
+		expandedLinks = append(expandedLinks, specificLinks...)
 	}
 
-	m.logFail(link, "link path is occupied")
-	return false
+	sort.Slice(expandedLinks, func(i int, j int) bool {
+		return expandedLinks[i].Name < expandedLinks[j].Name
+	})
+
+	return expandedLinks, nil
 }
 
 // CreateLinks creates links which are described in links parameter.
@@ -122,7 +378,7 @@ func (m linkMaker) CreateLinks(links []Link) (globalSuccess bool) {
 		action := makingAction{
 			Name: link.Name,
 			Perform: func() bool {
-				m.logFail(link, err.Error())
+				m.report(link, newOutcome(link, outcome.Failed, err))
 				return false
 			},
 		}
@@ -143,37 +399,16 @@ func (m linkMaker) CreateLinks(links []Link) (globalSuccess bool) {
 	makingActions := []makingAction{}
 
 	for _, link := range links {
-		// Creates making action if target isn't a directory
-		targetType := fsutility.GetPathType(link.TargetPath)
-		if targetType != fsutility.Directory {
-			action := createMakingAction(link)
-			makingActions = append(makingActions, action)
-			continue
-		}
-
-		// Reads all entries in the target directory
-		entryInfos, err := ioutil.ReadDir(link.TargetPath)
+		specificLinks, err := expandLink(link)
 		if err != nil {
 			action := createErrorAction(link, err)
 			makingActions = append(makingActions, action)
 			continue
 		}
 
-		// Makes an action for every entry in the target directory
-		for _, entryInfo := range entryInfos {
-			targetFileName := path.Base(entryInfo.Name())
-
-			specificName := link.Name + "/" + targetFileName
-			specificTargetFile := path.Join(link.TargetPath, targetFileName)
-			specificLinkPath := path.Join(link.LinkPath, targetFileName)
-
-			specificLink := Link{
-				Name:       specificName,
-				TargetPath: specificTargetFile,
-				LinkPath:   specificLinkPath,
-			}
-			specificAction := createMakingAction(specificLink)
-			makingActions = append(makingActions, specificAction)
+		for _, specificLink := range specificLinks {
+			action := createMakingAction(specificLink)
+			makingActions = append(makingActions, action)
 		}
 	}
 
@@ -5,9 +5,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"testing"
 
+	"errors"
 	"os"
 	"path"
 
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/pkg/fstestutility"
 	"github.com/backdround/deploy-configs/pkg/fsutility"
 )
@@ -35,7 +37,7 @@ func TestSuccessfulMakeLink(t *testing.T) {
 			TargetPath: targetFile,
 			LinkPath:   linkPath,
 		}
-		NewLinkMaker(loggerMock).makeLink(link)
+		NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 		// Asserts the created symlink
 		require.True(t, fsutility.IsLinkPointsToDestination(link.LinkPath,
@@ -65,7 +67,7 @@ func TestSuccessfulMakeLink(t *testing.T) {
 			LinkPath:   linkPath,
 		}
 
-		NewLinkMaker(loggerMock).makeLink(link)
+		NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 		// Asserts the created symlink
 		require.True(t, fsutility.IsLinkPointsToDestination(link.LinkPath,
@@ -95,7 +97,7 @@ func TestSuccessfulMakeLink(t *testing.T) {
 			TargetPath: targetFile,
 			LinkPath:   linkPath,
 		}
-		NewLinkMaker(loggerMock).makeLink(link)
+		NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 		// Asserts the created symlink
 		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
@@ -122,7 +124,7 @@ func TestFailedMakeLink(t *testing.T) {
 			TargetPath: targetFile,
 			LinkPath:   linkPath,
 		}
-		NewLinkMaker(loggerMock).makeLink(link)
+		NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 		// Asserts that the file on the link place wasn't deleted
 		linkType := fsutility.GetPathType(link.LinkPath)
@@ -151,7 +153,7 @@ func TestFailedMakeLink(t *testing.T) {
 			TargetPath: targetFile,
 			LinkPath:   linkPath,
 		}
-		NewLinkMaker(loggerMock).makeLink(link)
+		NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 		// Asserts that the file on the link place wasn't deleted
 		linkType := fsutility.GetPathType(link.LinkPath)
@@ -174,7 +176,7 @@ func TestFailedMakeLink(t *testing.T) {
 			TargetPath: targetFile,
 			LinkPath:   linkPath,
 		}
-		NewLinkMaker(loggerMock).makeLink(link)
+		NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 		// Asserts that files wasn't created
 		linkType := fsutility.GetPathType(linkPath)
@@ -196,7 +198,7 @@ func TestSkippedMakeLink(t *testing.T) {
 	// Sets up the mock
 	loggerMock := new(LoggerMock)
 	defer loggerMock.AssertExpectations(t)
-	loggerMock.On("Log", mock.Anything).Once()
+	loggerMock.On("Skip", mock.Anything).Once()
 
 	// Executes the test
 	link := Link{
@@ -204,12 +206,257 @@ func TestSkippedMakeLink(t *testing.T) {
 		TargetPath: targetFile,
 		LinkPath:   linkPath,
 	}
-	NewLinkMaker(loggerMock).makeLink(link)
+	NewLinkMaker(loggerMock, Options{}).makeLink(link)
 
 	// Asserts that the link exists
 	require.True(t, fsutility.IsLinkPointsToDestination(linkPath, targetFile))
 }
 
+func TestDryRunMakeLink(t *testing.T) {
+	t.Run("LinkDoesntExist", func(t *testing.T) {
+		// Creates a target file
+		targetFile := "target.*.txt"
+		cleanup := fstestutility.CreateTemporaryFiles(&targetFile)
+		defer cleanup()
+
+		// Makes a link path inside a notexisting directory
+		linkDirectory := fstestutility.GetAvailableTempPath()
+		linkPath := path.Join(linkDirectory, "link")
+		defer os.RemoveAll(linkDirectory)
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString("would be created")).Once()
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: targetFile,
+			LinkPath:   linkPath,
+		}
+		NewLinkMaker(loggerMock, Options{DryRun: true}).makeLink(link)
+
+		// Asserts that nothing was created
+		linkDirectoryType := fsutility.GetPathType(linkDirectory)
+		require.Equal(t, fsutility.Notexisting.String(),
+			linkDirectoryType.String())
+	})
+
+	t.Run("LinksAreIncorrect", func(t *testing.T) {
+		// Creates a link file
+		linkPath := fstestutility.GetAvailableTempPath()
+		err := os.Symlink("/dev/zero", linkPath)
+		require.NoError(t, err)
+		defer os.Remove(linkPath)
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString("would be replaced")).Once()
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+		}
+		NewLinkMaker(loggerMock, Options{DryRun: true}).makeLink(link)
+
+		// Asserts that the link wasn't changed
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			"/dev/zero"))
+	})
+
+	t.Run("LinkPathIsOccupied", func(t *testing.T) {
+		// Creates test files
+		targetFile := "target.*.txt"
+		linkPath := targetFile + ".link"
+		cleanup := fstestutility.CreateTemporaryFiles(&targetFile, &linkPath)
+		defer cleanup()
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Fail", containsString("link path is occupied")).Once()
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: targetFile,
+			LinkPath:   linkPath,
+		}
+		NewLinkMaker(loggerMock, Options{DryRun: true}).makeLink(link)
+	})
+}
+
+func TestBackupMakeLink(t *testing.T) {
+	t.Run("OccupyingFileIsMoved", func(t *testing.T) {
+		// Creates an occupying file
+		directory := t.TempDir()
+		linkPath := path.Join(directory, "link")
+		err := os.WriteFile(linkPath, []byte("data"), 0644)
+		require.NoError(t, err)
+		backupDirectory := path.Join(directory, "backups")
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString("backup: ")).Once()
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+			Backup:     true,
+		}
+		collector := &outcome.Collector{}
+		options := Options{
+			Recorder:        collector,
+			BackupDirectory: backupDirectory,
+		}
+		NewLinkMaker(loggerMock, options).makeLink(link)
+
+		// Asserts that the link is created and the file is moved
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			"/dev/null"))
+		backupPath := path.Join(backupDirectory, linkPath)
+		data, err := os.ReadFile(backupPath)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+
+		require.Len(t, collector.Outcomes, 1)
+		require.Equal(t, outcome.Replaced, collector.Outcomes[0].Action)
+		require.Equal(t, backupPath, collector.Outcomes[0].Backup)
+	})
+
+	t.Run("BackupIsRecordedRightAway", func(t *testing.T) {
+		// Creates an occupying file
+		directory := t.TempDir()
+		linkPath := path.Join(directory, "link")
+		err := os.WriteFile(linkPath, []byte("data"), 0644)
+		require.NoError(t, err)
+		backupDirectory := path.Join(directory, "backups")
+		backupPath := path.Join(backupDirectory, linkPath)
+
+		// Sets up the mock
+		recorderMock := new(BackupRecorderMock)
+		defer recorderMock.AssertExpectations(t)
+		recorderMock.On("RecordBackup", "test-link", linkPath, backupPath).
+			Return(nil).Once()
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+			Backup:     true,
+		}
+		options := Options{
+			BackupDirectory: backupDirectory,
+			BackupRecorder:  recorderMock,
+		}
+		NewLinkMaker(getLoggerDummy(), options).makeLink(link)
+
+		// Asserts that the link is created
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			"/dev/null"))
+	})
+
+	t.Run("UnrecordedBackupIsMovedBack", func(t *testing.T) {
+		// Creates an occupying file
+		directory := t.TempDir()
+		linkPath := path.Join(directory, "link")
+		err := os.WriteFile(linkPath, []byte("data"), 0644)
+		require.NoError(t, err)
+
+		// Sets up the mocks
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Fail", containsString("unable to record backup")).Once()
+
+		recorderMock := new(BackupRecorderMock)
+		recorderMock.On("RecordBackup", mock.Anything, mock.Anything,
+			mock.Anything).Return(errors.New("read-only manifest"))
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+			Backup:     true,
+		}
+		options := Options{
+			BackupDirectory: path.Join(directory, "backups"),
+			BackupRecorder:  recorderMock,
+		}
+		NewLinkMaker(loggerMock, options).makeLink(link)
+
+		// Asserts that the file is back
+		data, err := os.ReadFile(linkPath)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+	})
+
+	t.Run("GlobalBackupInDryRun", func(t *testing.T) {
+		// Creates an occupying file
+		directory := t.TempDir()
+		linkPath := path.Join(directory, "link")
+		err := os.WriteFile(linkPath, []byte("data"), 0644)
+		require.NoError(t, err)
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString("would be replaced")).Once()
+
+		// Executes the test
+		link := Link{
+			Name:       "test-link",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+		}
+		options := Options{
+			DryRun:          true,
+			Backup:          true,
+			BackupDirectory: path.Join(directory, "backups"),
+		}
+		NewLinkMaker(loggerMock, options).makeLink(link)
+
+		// Asserts that the file is left
+		require.Equal(t, fsutility.Regular.String(),
+			fsutility.GetPathType(linkPath).String())
+	})
+}
+
+func TestRecordedOutcome(t *testing.T) {
+	// Creates a link that points to a different destination
+	linkPath := fstestutility.GetAvailableTempPath()
+	err := os.Symlink("/dev/zero", linkPath)
+	fstestutility.AssertNoError(err)
+	defer os.Remove(linkPath)
+
+	// Executes the test
+	link := Link{
+		Name:       "test-link",
+		TargetPath: "/dev/null",
+		LinkPath:   linkPath,
+	}
+	collector := &outcome.Collector{}
+	options := Options{Recorder: collector}
+	NewLinkMaker(getLoggerDummy(), options).makeLink(link)
+
+	// Asserts the recorded outcome
+	require.Len(t, collector.Outcomes, 1)
+	recordedOutcome := collector.Outcomes[0]
+	require.Equal(t, outcome.Link, recordedOutcome.Kind)
+	require.Equal(t, outcome.Replaced, recordedOutcome.Action)
+	require.Equal(t, "test-link", recordedOutcome.Name)
+	require.Equal(t, "/dev/null", recordedOutcome.Source)
+	require.Equal(t, linkPath, recordedOutcome.Destination)
+}
+
 //////////////////////////////////////////////////////////
 // Links
 
@@ -234,7 +481,7 @@ func TestLinks(t *testing.T) {
 		}
 
 		// Executes the test
-		NewLinkMaker(getLoggerDummy()).CreateLinks(links)
+		NewLinkMaker(getLoggerDummy(), Options{}).CreateLinks(links)
 
 		// Asserts that the links are correct
 		require.True(t, fsutility.IsLinkPointsToDestination(link1Path,
@@ -272,7 +519,7 @@ func TestLinks(t *testing.T) {
 		}
 
 		// Executes the test
-		NewLinkMaker(getLoggerDummy()).CreateLinks(links)
+		NewLinkMaker(getLoggerDummy(), Options{}).CreateLinks(links)
 
 		// Asserts that the links are valid
 		expectedLink1Path := path.Join(linkPath, "target1")
@@ -283,3 +530,70 @@ func TestLinks(t *testing.T) {
 			fsutility.IsLinkPointsToDestination(expectedLink2Path, target2Path))
 	})
 }
+
+func TestConfirmedMakeLink(t *testing.T) {
+	prepare := func(t *testing.T) (Link, *ConfirmerMock) {
+		// Creates a link which points elsewhere
+		linkPath := fstestutility.GetAvailableTempPath()
+		err := os.Symlink("/dev/null", linkPath)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.Remove(linkPath) })
+
+		link := Link{
+			Name:       "test-link",
+			TargetPath: "/dev/zero",
+			LinkPath:   linkPath,
+		}
+		return link, &ConfirmerMock{}
+	}
+
+	t.Run("Confirmed", func(t *testing.T) {
+		link, confirmer := prepare(t)
+		defer confirmer.AssertExpectations(t)
+		confirmer.On("Confirm", containsString(`current target: "/dev/null"`),
+			link.LinkPath, []byte(nil)).Return(true).Once()
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewLinkMaker(getLoggerDummy(), options).makeLink(link)
+
+		// Asserts that the link is replaced
+		require.True(t, success)
+		require.True(t, fsutility.IsLinkPointsToDestination(link.LinkPath,
+			"/dev/zero"))
+	})
+
+	t.Run("Declined", func(t *testing.T) {
+		link, confirmer := prepare(t)
+		defer confirmer.AssertExpectations(t)
+		confirmer.On("Confirm", mock.Anything, mock.Anything, mock.Anything).
+			Return(false).Once()
+
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Skip", containsString("replacing is declined")).Once()
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewLinkMaker(loggerMock, options).makeLink(link)
+
+		// Asserts that the link is left
+		require.True(t, success)
+		require.True(t, fsutility.IsLinkPointsToDestination(link.LinkPath,
+			"/dev/null"))
+	})
+
+	t.Run("NewLinkIsCreatedWithoutConfirmation", func(t *testing.T) {
+		link, confirmer := prepare(t)
+		os.Remove(link.LinkPath)
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewLinkMaker(getLoggerDummy(), options).makeLink(link)
+
+		// Asserts that the link is created without asking
+		require.True(t, success)
+		confirmer.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything,
+			mock.Anything)
+	})
+}
@@ -0,0 +1,217 @@
+package links
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+func (m linkMaker) logPruneFail(link Link, reason string) {
+	description := shift(getDescription(link), 1)
+	errorMessage := shift("error: "+reason, 2)
+
+	message := fmt.Sprintf("Unable to prune %q link:\n%v\n%v",
+		link.Name, description, errorMessage)
+	m.logger.Fail(message)
+}
+
+func (m linkMaker) logPruneSuccess(link Link) {
+	pattern := "Link %q pruned:\n%v"
+	if m.options.DryRun {
+		pattern = "Link %q would be pruned:\n%v"
+	}
+
+	message := fmt.Sprintf(pattern, link.Name, shift(getDescription(link), 1))
+	m.logger.Success(message)
+}
+
+// getLinkDestination returns an absolute path that the link points to.
+func getLinkDestination(linkPath string) (string, error) {
+	destination, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !path.IsAbs(destination) {
+		destination = path.Join(path.Dir(linkPath), destination)
+	}
+
+	return path.Clean(destination), nil
+}
+
+// isInsideDirectory checks that the filePath is placed inside the directory.
+func isInsideDirectory(filePath string, directory string) bool {
+	directory = path.Clean(directory)
+	if directory == "/" {
+		return true
+	}
+	return strings.HasPrefix(path.Clean(filePath), directory+"/")
+}
+
+// findEntryLinks returns symlinks in the link path directory
+// of the directory link that point to entries of the target directory.
+func findEntryLinks(link Link) []Link {
+	if fsutility.GetPathType(link.TargetPath) != fsutility.Directory {
+		return nil
+	}
+
+	entries, err := os.ReadDir(link.LinkPath)
+	if err != nil {
+		return nil
+	}
+
+	entryLinks := []Link{}
+	for _, entry := range entries {
+		linkPath := path.Join(link.LinkPath, entry.Name())
+		if fsutility.GetPathType(linkPath) != fsutility.Symlink {
+			continue
+		}
+
+		destination, err := getLinkDestination(linkPath)
+		if err != nil || path.Dir(destination) != path.Clean(link.TargetPath) {
+			continue
+		}
+
+		entryLinks = append(entryLinks, Link{
+			Name:       link.Name + "/" + entry.Name(),
+			TargetPath: destination,
+			LinkPath:   linkPath,
+		})
+	}
+
+	return entryLinks
+}
+
+// pruneLink removes the stale link and returns what was done. Links that
+// don't point to their target anymore are changed by the user, so they
+// are left.
+func (m linkMaker) pruneLink(link Link, rootDirectory string) outcome.Outcome {
+	linkType := fsutility.GetPathType(link.LinkPath)
+	if linkType == fsutility.Notexisting {
+		return newOutcome(link, outcome.Removed, nil)
+	}
+	if linkType != fsutility.Symlink {
+		return newOutcome(link, outcome.Skipped, nil)
+	}
+
+	// Leaves links that point outside of the root directory
+	destination, err := getLinkDestination(link.LinkPath)
+	if err != nil {
+		return newOutcome(link, outcome.Failed, err)
+	}
+	if !isInsideDirectory(destination, rootDirectory) {
+		return newOutcome(link, outcome.Skipped, nil)
+	}
+	if destination != path.Clean(link.TargetPath) {
+		return newOutcome(link, outcome.Skipped, nil)
+	}
+
+	if !m.options.DryRun {
+		err = os.Remove(link.LinkPath)
+		if err != nil {
+			return newOutcome(link, outcome.Failed, err)
+		}
+	}
+
+	return newOutcome(link, outcome.Removed, nil)
+}
+
+// PruneLinks removes stale links. Stale links are deployedLinks and
+// links to entries of linked directories which are no longer described
+// in links. Only symlinks that point inside the rootDirectory are removed.
+func (m linkMaker) PruneLinks(links []Link, deployedLinks []Link,
+	rootDirectory string) (success bool) {
+	// Gets desired link paths
+	desiredLinkPaths := map[string]bool{}
+	unreadableDirectories := []string{}
+	candidates := append([]Link{}, deployedLinks...)
+	for _, link := range links {
+		desiredLinkPaths[path.Clean(link.LinkPath)] = true
+
+		specificLinks, err := expandLink(link)
+		if err != nil {
+			unreadableDirectories = append(unreadableDirectories, link.LinkPath)
+			continue
+		}
+
+		for _, specificLink := range specificLinks {
+			desiredLinkPaths[path.Clean(specificLink.LinkPath)] = true
+		}
+
+		candidates = append(candidates, findEntryLinks(link)...)
+	}
+
+	isDesired := func(link Link) bool {
+		if desiredLinkPaths[path.Clean(link.LinkPath)] {
+			return true
+		}
+
+		// Keeps links which desirability is unknown
+		for _, directory := range unreadableDirectories {
+			if isInsideDirectory(link.LinkPath, directory) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Gets unique stale links
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	staleLinks := []Link{}
+	seenLinkPaths := map[string]bool{}
+	for _, candidate := range candidates {
+		linkPath := path.Clean(candidate.LinkPath)
+		if seenLinkPaths[linkPath] || isDesired(candidate) {
+			continue
+		}
+		seenLinkPaths[linkPath] = true
+		staleLinks = append(staleLinks, candidate)
+	}
+
+	// Prunes stale links
+	success = true
+	for _, link := range staleLinks {
+		alreadyRemoved :=
+			fsutility.GetPathType(link.LinkPath) == fsutility.Notexisting
+
+		started := time.Now()
+		linkOutcome := m.pruneLink(link, rootDirectory)
+		linkOutcome.Duration = time.Since(started)
+
+		switch {
+		case linkOutcome.Action == outcome.Failed:
+			m.logPruneFail(link, linkOutcome.Error)
+			success = false
+		case linkOutcome.Action == outcome.Skipped:
+			m.logSkip(link, linkOutcome.Reason)
+		case alreadyRemoved:
+			m.logger.Skip(fmt.Sprintf("Link %q is already removed",
+				link.Name))
+		default:
+			m.logPruneSuccess(Link{
+				Name:       link.Name,
+				TargetPath: linkOutcome.Source,
+				LinkPath:   link.LinkPath,
+			})
+		}
+
+		// Skipped links are left in place, but they aren't owned anymore
+		if linkOutcome.Action == outcome.Skipped {
+			linkOutcome.Disowned = true
+		}
+		if m.options.Recorder != nil {
+			m.options.Recorder.Record(linkOutcome)
+		}
+	}
+
+	return success
+}
@@ -0,0 +1,186 @@
+package links
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+
+	"os"
+	"path"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/fstestutility"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+// makePruneTestTree creates a root directory with a target directory
+// and a link directory inside that contains links to the targets.
+func makePruneTestTree(t *testing.T, targets ...string) (
+	rootDirectory string, targetDirectory string, linkDirectory string) {
+	rootDirectory = t.TempDir()
+	targetDirectory = path.Join(rootDirectory, "configs")
+	linkDirectory = path.Join(rootDirectory, "home")
+
+	for _, directory := range []string{targetDirectory, linkDirectory} {
+		err := os.Mkdir(directory, 0755)
+		require.NoError(t, err)
+	}
+
+	for _, target := range targets {
+		targetPath := path.Join(targetDirectory, target)
+		err := os.WriteFile(targetPath, []byte{}, 0644)
+		require.NoError(t, err)
+
+		err = os.Symlink(targetPath, path.Join(linkDirectory, target))
+		require.NoError(t, err)
+	}
+
+	return rootDirectory, targetDirectory, linkDirectory
+}
+
+func TestPruneLinks(t *testing.T) {
+	t.Run("DeployedLinkIsRemovedFromConfig", func(t *testing.T) {
+		rootDirectory, targetDirectory, linkDirectory :=
+			makePruneTestTree(t, "kept", "stale")
+
+		keptLink := Link{
+			Name:       "kept",
+			TargetPath: path.Join(targetDirectory, "kept"),
+			LinkPath:   path.Join(linkDirectory, "kept"),
+		}
+		staleLink := Link{
+			Name:       "stale",
+			TargetPath: path.Join(targetDirectory, "stale"),
+			LinkPath:   path.Join(linkDirectory, "stale"),
+		}
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString(`"stale" pruned`)).Once()
+
+		// Executes the test
+		collector := &outcome.Collector{}
+		linkMaker := NewLinkMaker(loggerMock, Options{Recorder: collector})
+		success := linkMaker.PruneLinks([]Link{keptLink},
+			[]Link{keptLink, staleLink}, rootDirectory)
+
+		// Asserts the result
+		require.True(t, success)
+		require.Equal(t, fsutility.Symlink.String(),
+			fsutility.GetPathType(keptLink.LinkPath).String())
+		require.Equal(t, fsutility.Notexisting.String(),
+			fsutility.GetPathType(staleLink.LinkPath).String())
+
+		require.Len(t, collector.Outcomes, 1)
+		require.Equal(t, outcome.Removed, collector.Outcomes[0].Action)
+		require.Equal(t, staleLink.LinkPath, collector.Outcomes[0].Destination)
+	})
+
+	t.Run("EntryIsRemovedFromLinkedDirectory", func(t *testing.T) {
+		rootDirectory, targetDirectory, linkDirectory :=
+			makePruneTestTree(t, "kept", "stale")
+		err := os.Remove(path.Join(targetDirectory, "stale"))
+		require.NoError(t, err)
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString(`"configs/stale" pruned`)).Once()
+
+		// Executes the test
+		directoryLink := Link{
+			Name:       "configs",
+			TargetPath: targetDirectory,
+			LinkPath:   linkDirectory,
+		}
+		linkMaker := NewLinkMaker(loggerMock, Options{})
+		success := linkMaker.PruneLinks([]Link{directoryLink}, nil,
+			rootDirectory)
+
+		// Asserts the result
+		require.True(t, success)
+		require.Equal(t, fsutility.Symlink.String(),
+			fsutility.GetPathType(path.Join(linkDirectory, "kept")).String())
+		require.Equal(t, fsutility.Notexisting.String(),
+			fsutility.GetPathType(path.Join(linkDirectory, "stale")).String())
+	})
+
+	t.Run("LinkPointsOutsideOfRootDirectory", func(t *testing.T) {
+		linkPath := fstestutility.GetAvailableTempPath()
+		err := os.Symlink("/dev/null", linkPath)
+		require.NoError(t, err)
+		defer os.Remove(linkPath)
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Skip", containsString("skipped")).Once()
+
+		// Executes the test
+		staleLink := Link{
+			Name:       "stale",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+		}
+		linkMaker := NewLinkMaker(loggerMock, Options{})
+		success := linkMaker.PruneLinks(nil, []Link{staleLink}, t.TempDir())
+
+		// Asserts that the link is left
+		require.True(t, success)
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			"/dev/null"))
+	})
+
+	t.Run("LinkIsRepointedByUser", func(t *testing.T) {
+		rootDirectory, targetDirectory, linkDirectory :=
+			makePruneTestTree(t, "other")
+		linkPath := path.Join(linkDirectory, "other")
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Skip", containsString("skipped")).Once()
+
+		// Executes the test
+		staleLink := Link{
+			Name:       "stale",
+			TargetPath: path.Join(targetDirectory, "stale"),
+			LinkPath:   linkPath,
+		}
+		collector := &outcome.Collector{}
+		linkMaker := NewLinkMaker(loggerMock, Options{Recorder: collector})
+		success := linkMaker.PruneLinks(nil, []Link{staleLink}, rootDirectory)
+
+		// Asserts that the link is left and disowned
+		require.True(t, success)
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			path.Join(targetDirectory, "other")))
+		require.Len(t, collector.Outcomes, 1)
+		require.True(t, collector.Outcomes[0].Disowned)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		rootDirectory, targetDirectory, linkDirectory :=
+			makePruneTestTree(t, "stale")
+
+		staleLink := Link{
+			Name:       "stale",
+			TargetPath: path.Join(targetDirectory, "stale"),
+			LinkPath:   path.Join(linkDirectory, "stale"),
+		}
+
+		// Sets up the mock
+		loggerMock := new(LoggerMock)
+		defer loggerMock.AssertExpectations(t)
+		loggerMock.On("Success", containsString("would be pruned")).Once()
+
+		// Executes the test
+		linkMaker := NewLinkMaker(loggerMock, Options{DryRun: true})
+		success := linkMaker.PruneLinks(nil, []Link{staleLink}, rootDirectory)
+
+		// Asserts that the link is left
+		require.True(t, success)
+		require.Equal(t, fsutility.Symlink.String(),
+			fsutility.GetPathType(staleLink.LinkPath).String())
+	})
+}
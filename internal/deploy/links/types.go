@@ -1,15 +1,46 @@
 package links
 
+import "github.com/backdround/deploy-configs/internal/deploy/outcome"
+
 // Link is a stracture that represents symbolic link
 // to create by this package.
 type Link struct {
 	Name       string
 	TargetPath string
 	LinkPath   string
+	// Backup allows to move an occupying file to the backup directory
+	// instead of failing.
+	Backup bool
+}
+
+// Options adjusts the behaviour of linkMaker.
+type Options struct {
+	// DryRun makes linkMaker only log planned changes without
+	// touching the filesystem.
+	DryRun bool
+
+	// Recorder receives outcomes of all deployed links. It's optional.
+	Recorder outcome.Recorder
+
+	// Confirmer is asked before replacing a link that points elsewhere.
+	// It's optional.
+	Confirmer outcome.Confirmer
+
+	// Backup enables backups for all links.
+	Backup bool
+
+	// BackupDirectory is a directory where occupying files are moved to.
+	// Their absolute paths are preserved inside it.
+	BackupDirectory string
+
+	// BackupRecorder saves every backup right after the move. It's
+	// optional.
+	BackupRecorder outcome.BackupRecorder
 }
 
 type Logger interface {
 	Success(message string)
 	Fail(message string)
 	Log(message string)
+	Skip(message string)
 }
@@ -0,0 +1,86 @@
+// outcome describes results of deploying units. It's shared between
+// deploy packages and consumers of their results.
+package outcome
+
+import "time"
+
+// Kind represents a kind of deployed unit
+type Kind string
+
+const (
+	Link     Kind = "link"
+	Template Kind = "template"
+	Command  Kind = "command"
+)
+
+// Action represents what was done with a deployed unit
+type Action string
+
+const (
+	Created  Action = "created"
+	Replaced Action = "replaced"
+	Skipped  Action = "skipped"
+	Failed   Action = "failed"
+	// Removed is used for stale units that were pruned.
+	Removed Action = "removed"
+)
+
+// Outcome represents a result of deploying a single unit
+type Outcome struct {
+	Kind   Kind
+	Name   string
+	Action Action
+	// Source is a link target or an input path.
+	Source string
+	// Destination is a link path or an output path.
+	Destination string
+	// Hash is a sha512 of the destination file. It's empty for links.
+	Hash []byte
+	// Command is an expanded command line with the output path instead
+	// of the temporary one that is used to execute it. It's empty for
+	// links and templates.
+	Command string
+	// CreatedDirectories are directories that were created to place
+	// the destination.
+	CreatedDirectories []string
+	// Backup is a path where an occupying file was moved to.
+	Backup string
+	// Disowned marks a unit that is left in place, but isn't deployed
+	// by the instance anymore. It's dropped from the manifest.
+	Disowned bool
+	// Reason explains why a unit is skipped without deploying, e.g. its
+	// condition is false. Such units aren't recorded to the manifest.
+	Reason   string
+	Error    string
+	Duration time.Duration
+}
+
+// Recorder receives outcomes of deployed units.
+type Recorder interface {
+	Record(outcome Outcome)
+}
+
+// BackupRecorder saves a backup right after an occupying file is moved
+// away, so the backup isn't lost if the run is interrupted before its
+// outcome is recorded.
+type BackupRecorder interface {
+	RecordBackup(name string, originalPath string, backupPath string) error
+}
+
+// Confirmer asks whether a destructive action is allowed before it's
+// done.
+type Confirmer interface {
+	// Confirm receives a description of the action. If the action
+	// overwrites the output path with newData, then newData isn't nil and
+	// the difference can be shown.
+	Confirm(description string, outputPath string, newData []byte) bool
+}
+
+// Collector is a Recorder that keeps all recorded outcomes.
+type Collector struct {
+	Outcomes []Outcome
+}
+
+func (c *Collector) Record(outcome Outcome) {
+	c.Outcomes = append(c.Outcomes, outcome)
+}
@@ -0,0 +1,31 @@
+package outcome
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+// CheckOutputIsUnmodified returns an error if the output file was
+// modified after the last deploying. deployedHashes are hashes of
+// deployed outputs by their paths. Outputs that weren't deployed and
+// forced outputs aren't checked.
+func CheckOutputIsUnmodified(outputPath string,
+	deployedHashes map[string][]byte, force bool) error {
+	deployedHash, deployed := deployedHashes[outputPath]
+	if !deployed || force {
+		return nil
+	}
+
+	if fsutility.GetPathType(outputPath) != fsutility.Regular {
+		return nil
+	}
+
+	if bytes.Equal(fsutility.GetFileHash(outputPath), deployedHash) {
+		return nil
+	}
+
+	return errors.New("output file was modified after deploying " +
+		"(see the diff command or use --force to overwrite it)")
+}
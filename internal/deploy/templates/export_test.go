@@ -25,6 +25,23 @@ func (l *LoggerMock) Log(message string) {
 	l.Called(message)
 }
 
+func (l *LoggerMock) Skip(message string) {
+	l.Called(message)
+}
+
+////////////////////////////////////////////////////////////
+// ConfirmerMock
+
+type ConfirmerMock struct {
+	mock.Mock
+}
+
+func (c *ConfirmerMock) Confirm(description string, outputPath string,
+	newData []byte) bool {
+	args := c.Called(description, outputPath, newData)
+	return args.Bool(0)
+}
+
 ////////////////////////////////////////////////////////////
 // Utility functions
 
@@ -4,23 +4,27 @@ package templates
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"os"
 	"path"
 	"sort"
 	templatePackage "text/template"
+	"time"
 
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/pkg/fsutility"
 	"github.com/backdround/go-indent"
 )
 
 type templateMaker struct {
-	logger Logger
+	logger  Logger
+	options Options
 }
 
-func NewTemplateMaker(logger Logger) templateMaker {
+func NewTemplateMaker(logger Logger, options Options) templateMaker {
 	return templateMaker{
-		logger: logger,
+		logger:  logger,
+		options: options,
 	}
 }
 
@@ -49,67 +53,217 @@ func (m templateMaker) logSuccess(template Template) {
 	m.logger.Success(message)
 }
 
-func (m templateMaker) logSkip(template Template) {
+func (m templateMaker) logSkip(template Template, reason string) {
 	message := fmt.Sprintf("Template %q is skipped", template.Name)
-	m.logger.Log(message)
+	if reason != "" {
+		message += ", because " + reason
+	}
+	m.logger.Skip(message)
 }
 
-func (m templateMaker) makeTemplate(t Template) (success bool) {
+func (m templateMaker) logPlan(template Template, action outcome.Action) {
+	message := fmt.Sprintf("Template %q would be %v:\n%v",
+		template.Name, action, shift(getDescription(template), 1))
+	m.logger.Success(message)
+}
+
+// report logs and records the outcome of the template expanding.
+func (m templateMaker) report(t Template, templateOutcome outcome.Outcome) {
+	switch {
+	case templateOutcome.Action == outcome.Failed:
+		m.logFail(t, templateOutcome.Error)
+	case templateOutcome.Action == outcome.Skipped:
+		m.logSkip(t, templateOutcome.Reason)
+	case m.options.DryRun:
+		m.logPlan(t, templateOutcome.Action)
+	default:
+		m.logSuccess(t)
+	}
+
+	if m.options.Recorder != nil {
+		m.options.Recorder.Record(templateOutcome)
+	}
+}
+
+// newOutcome creates an outcome of the template expanding.
+func newOutcome(t Template, action outcome.Action, hash []byte,
+	err error) outcome.Outcome {
+	templateOutcome := outcome.Outcome{
+		Kind:        outcome.Template,
+		Name:        t.Name,
+		Action:      action,
+		Source:      t.InputPath,
+		Destination: t.OutputPath,
+		Hash:        hash,
+	}
+
+	if err != nil {
+		templateOutcome.Action = outcome.Failed
+		templateOutcome.Hash = nil
+		templateOutcome.Error = err.Error()
+	}
+
+	return templateOutcome
+}
+
+// getData returns data to execute the template with. Variables are
+// added to map data as "Vars" key if the data doesn't have it yet.
+func getData(t Template) interface{} {
+	if t.Variables == nil {
+		return t.Data
+	}
+
+	switch data := t.Data.(type) {
+	case nil:
+		return map[string]interface{}{"Vars": t.Variables}
+	case map[string]interface{}:
+		if _, ok := data["Vars"]; ok {
+			return data
+		}
+
+		dataWithVariables := map[string]interface{}{"Vars": t.Variables}
+		for key, value := range data {
+			dataWithVariables[key] = value
+		}
+		return dataWithVariables
+	default:
+		return t.Data
+	}
+}
+
+// Render expands the template in memory without touching the output path.
+func Render(t Template) ([]byte, error) {
 	// Checks input file existence
 	inputType := fsutility.GetPathType(t.InputPath)
 	if inputType != fsutility.Regular && inputType != fsutility.Symlink {
-		m.logFail(t, "input file doesn't exist")
-		return false
+		return nil, errors.New("input file doesn't exist")
 	}
 
-	// Gets expanded data
 	template, err := templatePackage.ParseFiles(t.InputPath)
 	if err != nil {
-		m.logFail(t, err.Error())
-		return false
+		return nil, err
 	}
 
 	outputBuffer := bytes.NewBuffer([]byte{})
-	err = template.Option("missingkey=error").Execute(outputBuffer, t.Data)
+	err = template.Option("missingkey=error").Execute(outputBuffer, getData(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return outputBuffer.Bytes(), nil
+}
+
+// planTemplate returns what deployTemplate would do with the expanded
+// template without touching the filesystem.
+func (m templateMaker) planTemplate(t Template) (outcome.Action, error) {
+	// Checks the output file directory
+	err := fsutility.CheckDirectoryCanBeMade(path.Dir(t.OutputPath))
+	if err != nil {
+		return outcome.Failed, err
+	}
+
+	switch fsutility.GetPathType(t.OutputPath) {
+	case fsutility.Notexisting:
+		return outcome.Created, nil
+	case fsutility.Directory:
+		return outcome.Failed, errors.New("output path is a directory")
+	}
+
+	return outcome.Replaced, nil
+}
+
+// confirmOverwriting asks the confirmer whether the existing output can
+// be overwritten with the expanded data.
+func (m templateMaker) confirmOverwriting(t Template,
+	expandedData []byte) bool {
+	if m.options.Confirmer == nil {
+		return true
+	}
+
+	description := fmt.Sprintf("Template %q output is going to be "+
+		"overwritten:\n%v", t.Name, shift(getDescription(t), 1))
+	return m.options.Confirmer.Confirm(description, t.OutputPath,
+		expandedData)
+}
+
+// deployTemplate expands the template to the output path and returns
+// what was done.
+func (m templateMaker) deployTemplate(t Template) outcome.Outcome {
+	fail := func(err error) outcome.Outcome {
+		return newOutcome(t, outcome.Failed, nil, err)
+	}
+
+	// Gets expanded data
+	expandedData, err := Render(t)
 	if err != nil {
-		m.logFail(t, err.Error())
-		return false
+		return fail(err)
 	}
 
 	// Checks if the output file is already expanded
 	oldOutputFileHash := fsutility.GetFileHash(t.OutputPath)
-	newOutputFileHash := fsutility.GetHash(outputBuffer.Bytes())
+	newOutputFileHash := fsutility.GetHash(expandedData)
 	if bytes.Equal(oldOutputFileHash, newOutputFileHash) {
-		m.logSkip(t)
-		return true
+		return newOutcome(t, outcome.Skipped, newOutputFileHash, nil)
 	}
 
-	// Creates the output file directory
-	err = fsutility.MakeDirectoryIfDoesntExist(path.Dir(t.OutputPath))
+	// Protects the output file from overwriting local modifications
+	err = outcome.CheckOutputIsUnmodified(t.OutputPath,
+		m.options.DeployedHashes, m.options.Force)
 	if err != nil {
-		m.logFail(t, err.Error())
-		return false
+		return fail(err)
+	}
+
+	if m.options.DryRun {
+		action, err := m.planTemplate(t)
+		return newOutcome(t, action, newOutputFileHash, err)
 	}
 
-	// Removes output path if it's a link.
-	outputType := fsutility.GetPathType(t.OutputPath)
-	if outputType == fsutility.Symlink {
-		err := os.Remove(t.OutputPath)
-		if err != nil {
-			m.logFail(t, err.Error())
-			return false
+	// Checks the output path
+	action := outcome.Created
+	switch fsutility.GetPathType(t.OutputPath) {
+	case fsutility.Notexisting:
+	case fsutility.Directory:
+		return fail(errors.New("output path is a directory"))
+	default:
+		// Asks before overwriting the existing output
+		if !m.confirmOverwriting(t, expandedData) {
+			declined := newOutcome(t, outcome.Skipped, nil, nil)
+			declined.Reason = "overwriting is declined"
+			return declined
 		}
+		action = outcome.Replaced
 	}
 
-	// Creates the expanded file
-	err = os.WriteFile(t.OutputPath, outputBuffer.Bytes(), 0644)
+	// Creates the output file directory
+	createdDirectories, err := fsutility.MakeDirectoryIfDoesntExist(
+		path.Dir(t.OutputPath))
 	if err != nil {
-		m.logFail(t, err.Error())
-		return false
+		return fail(err)
+	}
+
+	result := func(action outcome.Action, err error) outcome.Outcome {
+		templateOutcome := newOutcome(t, action, newOutputFileHash, err)
+		templateOutcome.CreatedDirectories = createdDirectories
+		return templateOutcome
 	}
 
-	m.logSuccess(t)
-	return true
+	// Creates the expanded file. The old output (or a link on its place)
+	// is replaced only after the whole data is written.
+	err = fsutility.WriteFileAtomically(t.OutputPath, expandedData, 0644)
+	if err != nil {
+		return result(outcome.Failed, err)
+	}
+
+	return result(action, nil)
+}
+
+// makeTemplate expands the template and logs the outcome.
+func (m templateMaker) makeTemplate(t Template) (success bool) {
+	started := time.Now()
+	templateOutcome := m.deployTemplate(t)
+	templateOutcome.Duration = time.Since(started)
+	m.report(t, templateOutcome)
+	return templateOutcome.Action != outcome.Failed
 }
 
 // MakeTemplates expands the given templates.
@@ -121,7 +275,9 @@ func (m templateMaker) MakeTemplates(templates []Template) (success bool) {
 
 	success = true
 	for _, template := range templates {
-		success = success && m.makeTemplate(template)
+		if !m.makeTemplate(template) {
+			success = false
+		}
 	}
 	return success
 }
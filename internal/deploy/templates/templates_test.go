@@ -5,6 +5,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/backdround/deploy-configs/pkg/fstestutility"
@@ -40,7 +41,7 @@ func TestSuccessfulMakeTemplate(t *testing.T) {
 		logger.On("Success", containsString("test-template")).Once()
 
 		// Executes the test
-		NewTemplateMaker(logger).makeTemplate(template)
+		NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 		// Asserts that the output file exists and expanded
 		outputPathType := fsutility.GetPathType(outputPath)
@@ -81,7 +82,7 @@ func TestSuccessfulMakeTemplate(t *testing.T) {
 		logger.On("Success", containsString("test-template")).Once()
 
 		// Executes the test
-		NewTemplateMaker(logger).makeTemplate(template)
+		NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 		// Asserts that the output file exists and expanded
 		outputPathType := fsutility.GetPathType(outputPath)
@@ -121,7 +122,7 @@ func TestSuccessfulMakeTemplate(t *testing.T) {
 		logger.On("Success", containsString("test-template")).Once()
 
 		// Executes the test
-		NewTemplateMaker(logger).makeTemplate(template)
+		NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 		// Asserts that the output file exists and expanded
 		outputPathType := fsutility.GetPathType(outputPath)
@@ -151,7 +152,7 @@ func TestFailMakeTemplate(t *testing.T) {
 		logger.On("Fail", containsString("test-template")).Once()
 
 		// Executes the test
-		NewTemplateMaker(logger).makeTemplate(template)
+		NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 		// Asserts that an output file doesn't exist
 		outputPathType := fsutility.GetPathType(outputPath)
@@ -178,7 +179,7 @@ func TestFailMakeTemplate(t *testing.T) {
 		logger.On("Fail", containsString("test-template")).Once()
 
 		// Executes the test
-		NewTemplateMaker(logger).makeTemplate(template)
+		NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 		// Asserts that an output file doesn't exist
 		outputPathType := fsutility.GetPathType(template.OutputPath)
@@ -205,7 +206,7 @@ func TestFailMakeTemplate(t *testing.T) {
 		logger.On("Fail", containsString("test-template")).Once()
 
 		// Executes the test
-		NewTemplateMaker(logger).makeTemplate(template)
+		NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 		// Asserts that a output file doesn't exist
 		outputPathType := fsutility.GetPathType(template.OutputPath)
@@ -239,10 +240,10 @@ func TestSkipMakeTemplate(t *testing.T) {
 
 	logger := &LoggerMock{}
 	defer logger.AssertExpectations(t)
-	logger.On("Log", containsString("test-template")).Once()
+	logger.On("Skip", containsString("test-template")).Once()
 
 	// Executes the test
-	NewTemplateMaker(logger).makeTemplate(template)
+	NewTemplateMaker(logger, Options{}).makeTemplate(template)
 
 	// Asserts that the output file exists
 	outputPathType := fsutility.GetPathType(outputFile)
@@ -253,3 +254,241 @@ func TestSkipMakeTemplate(t *testing.T) {
 	fstestutility.AssertNoError(err)
 	require.Equal(t, "value1 value2", string(resultData))
 }
+
+func TestDryRunMakeTemplate(t *testing.T) {
+	t.Run("OutputFileDoesntExist", func(t *testing.T) {
+		// Creates a template file
+		templateFile, cleanup :=
+			fstestutility.CreateTemporaryFileWithData("{{.var1}}")
+		defer cleanup()
+
+		// Creates test data
+		template := Template{
+			Name:       "test-template",
+			InputPath:  templateFile,
+			OutputPath: fstestutility.GetAvailableTempPath(),
+			Data:       map[string]string{"var1": "value1"},
+		}
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("would be created")).Once()
+
+		// Executes the test
+		options := Options{DryRun: true}
+		NewTemplateMaker(logger, options).makeTemplate(template)
+
+		// Asserts that the output file wasn't created
+		outputPathType := fsutility.GetPathType(template.OutputPath)
+		require.Equal(t, fsutility.Notexisting.String(), outputPathType.String())
+	})
+
+	t.Run("OutputFileExists", func(t *testing.T) {
+		// Creates a template file
+		templateFile, templateCleanup :=
+			fstestutility.CreateTemporaryFileWithData("{{.var1}}")
+		defer templateCleanup()
+
+		// Creates an output file
+		outputPath, outputCleanup :=
+			fstestutility.CreateTemporaryFileWithData("some file data")
+		defer outputCleanup()
+
+		// Creates test data
+		template := Template{
+			Name:       "test-template",
+			InputPath:  templateFile,
+			OutputPath: outputPath,
+			Data:       map[string]string{"var1": "value1"},
+		}
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("would be replaced")).Once()
+
+		// Executes the test
+		options := Options{DryRun: true}
+		NewTemplateMaker(logger, options).makeTemplate(template)
+
+		// Asserts that the output file wasn't changed
+		resultData, err := os.ReadFile(outputPath)
+		fstestutility.AssertNoError(err)
+		require.Equal(t, "some file data", string(resultData))
+	})
+
+	t.Run("OutputFileIsExpanded", func(t *testing.T) {
+		// Creates a template file
+		templateFile, templateCleanup :=
+			fstestutility.CreateTemporaryFileWithData("{{.var1}}")
+		defer templateCleanup()
+
+		// Creates an output file
+		outputPath, outputCleanup :=
+			fstestutility.CreateTemporaryFileWithData("value1")
+		defer outputCleanup()
+
+		// Creates test data
+		template := Template{
+			Name:       "test-template",
+			InputPath:  templateFile,
+			OutputPath: outputPath,
+			Data:       map[string]string{"var1": "value1"},
+		}
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Skip", containsString("is skipped")).Once()
+
+		// Executes the test
+		options := Options{DryRun: true}
+		NewTemplateMaker(logger, options).makeTemplate(template)
+	})
+}
+
+func TestModifiedOutputMakeTemplate(t *testing.T) {
+	// makeTemplate creates a template and its modified output file.
+	makeTemplate := func(t *testing.T) (Template, Options) {
+		templateFile, templateCleanup :=
+			fstestutility.CreateTemporaryFileWithData("{{.var1}}")
+		t.Cleanup(templateCleanup)
+
+		outputPath, outputCleanup :=
+			fstestutility.CreateTemporaryFileWithData("modified data")
+		t.Cleanup(outputCleanup)
+
+		template := Template{
+			Name:       "test-template",
+			InputPath:  templateFile,
+			OutputPath: outputPath,
+			Data:       map[string]string{"var1": "value1"},
+		}
+		options := Options{
+			DeployedHashes: map[string][]byte{
+				outputPath: fsutility.GetHash([]byte("deployed data")),
+			},
+		}
+		return template, options
+	}
+
+	t.Run("OutputFileIsProtected", func(t *testing.T) {
+		template, options := makeTemplate(t)
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Fail", containsString("modified after deploying")).Once()
+
+		// Executes the test
+		NewTemplateMaker(logger, options).makeTemplate(template)
+
+		// Asserts that the output file wasn't changed
+		data, err := os.ReadFile(template.OutputPath)
+		require.NoError(t, err)
+		require.Equal(t, "modified data", string(data))
+	})
+
+	t.Run("OutputFileIsForced", func(t *testing.T) {
+		template, options := makeTemplate(t)
+		options.Force = true
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("expanded")).Once()
+
+		// Executes the test
+		NewTemplateMaker(logger, options).makeTemplate(template)
+
+		// Asserts that the output file was overwritten
+		data, err := os.ReadFile(template.OutputPath)
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(data))
+	})
+}
+
+func TestRenderWithVariables(t *testing.T) {
+	templateFile, cleanup :=
+		fstestutility.CreateTemporaryFileWithData("{{.Vars.editor}} {{.var1}}")
+	defer cleanup()
+
+	template := Template{
+		Name:      "test-template",
+		InputPath: templateFile,
+		Data: map[string]interface{}{
+			"var1": "value1",
+		},
+		Variables: map[string]interface{}{
+			"editor": "vim",
+		},
+	}
+
+	// Executes the test
+	data, err := Render(template)
+
+	// Asserts that variables are available
+	require.NoError(t, err)
+	require.Equal(t, "vim value1", string(data))
+}
+
+func TestConfirmedMakeTemplate(t *testing.T) {
+	prepare := func(t *testing.T) Template {
+		templateFile, cleanup :=
+			fstestutility.CreateTemporaryFileWithData("new data")
+		t.Cleanup(cleanup)
+
+		// Creates an existing output file
+		outputPath, outputCleanup :=
+			fstestutility.CreateTemporaryFileWithData("old data")
+		t.Cleanup(outputCleanup)
+
+		return Template{
+			Name:       "test-template",
+			InputPath:  templateFile,
+			OutputPath: outputPath,
+		}
+	}
+
+	t.Run("Confirmed", func(t *testing.T) {
+		template := prepare(t)
+
+		confirmer := &ConfirmerMock{}
+		defer confirmer.AssertExpectations(t)
+		confirmer.On("Confirm", containsString("test-template"),
+			template.OutputPath, []byte("new data")).Return(true).Once()
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("test-template")).Once()
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewTemplateMaker(logger, options).makeTemplate(template)
+
+		// Asserts that the output file is overwritten
+		require.True(t, success)
+		data, err := os.ReadFile(template.OutputPath)
+		require.NoError(t, err)
+		require.Equal(t, "new data", string(data))
+	})
+
+	t.Run("Declined", func(t *testing.T) {
+		template := prepare(t)
+
+		confirmer := &ConfirmerMock{}
+		defer confirmer.AssertExpectations(t)
+		confirmer.On("Confirm", mock.Anything, mock.Anything, mock.Anything).
+			Return(false).Once()
+
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Skip", containsString("overwriting is declined")).Once()
+
+		// Executes the test
+		options := Options{Confirmer: confirmer}
+		success := NewTemplateMaker(logger, options).makeTemplate(template)
+
+		// Asserts that the output file is left
+		require.True(t, success)
+		data, err := os.ReadFile(template.OutputPath)
+		require.NoError(t, err)
+		require.Equal(t, "old data", string(data))
+	})
+}
@@ -1,15 +1,42 @@
 package templates
 
+import "github.com/backdround/deploy-configs/internal/deploy/outcome"
+
 // Template represents template to expand by this package
 type Template struct {
 	Name       string
 	InputPath  string
 	OutputPath string
 	Data       interface{}
+	// Variables are available in the template as .Vars if Data is a map.
+	Variables map[string]interface{}
+}
+
+// Options adjusts the behaviour of templateMaker.
+type Options struct {
+	// DryRun makes templateMaker only log planned changes without
+	// touching the filesystem.
+	DryRun bool
+
+	// Recorder receives outcomes of all expanded templates. It's optional.
+	Recorder outcome.Recorder
+
+	// Confirmer is asked before overwriting an existing output. It's
+	// optional.
+	Confirmer outcome.Confirmer
+
+	// DeployedHashes are hashes of outputs by their paths which were
+	// deployed last time. templateMaker refuses to overwrite outputs which
+	// were modified after deploying. It's optional.
+	DeployedHashes map[string][]byte
+
+	// Force allows to overwrite modified outputs.
+	Force bool
 }
 
 type Logger interface {
 	Success(message string)
 	Fail(message string)
 	Log(message string)
+	Skip(message string)
 }
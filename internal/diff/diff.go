@@ -0,0 +1,149 @@
+// diff describes differ which expands templates and commands without
+// touching their outputs and logs unified diffs between the current
+// outputs and the expanded ones.
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/backdround/deploy-configs/internal/deploy/commands"
+	"github.com/backdround/deploy-configs/internal/deploy/templates"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/go-indent"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+type differ struct {
+	logger Logger
+}
+
+func NewDiffer(logger Logger) differ {
+	return differ{
+		logger: logger,
+	}
+}
+
+func shift(message string, count int) string {
+	return indent.Indent(message, "  ", count)
+}
+
+// splitLines splits data into lines which keep their line endings.
+// A missing line ending of the last line is marked as diff does.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+
+	lastLine := lines[len(lines)-1]
+	if lastLine == "" {
+		return lines[:len(lines)-1]
+	}
+
+	lines[len(lines)-1] = lastLine + "\n\\ No newline at end of file\n"
+	return lines
+}
+
+// GetUnifiedDiff returns a unified diff between the file on the
+// outputPath and the expectedData. It returns an empty string if
+// there is no difference.
+func GetUnifiedDiff(outputPath string, expectedData []byte) (string, error) {
+	currentData := []byte{}
+	currentName := outputPath
+	switch fsutility.GetPathType(outputPath) {
+	case fsutility.Notexisting:
+		currentName = "/dev/null"
+	case fsutility.Directory:
+		return "", errors.New("output path is a directory")
+	default:
+		var err error
+		currentData, err = os.ReadFile(outputPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	unifiedDiff := difflib.UnifiedDiff{
+		A:        splitLines(currentData),
+		B:        splitLines(expectedData),
+		FromFile: currentName,
+		FromDate: "current",
+		ToFile:   outputPath,
+		ToDate:   "expanded",
+		Context:  3,
+	}
+
+	return difflib.GetUnifiedDiffString(unifiedDiff)
+}
+
+// logDiff logs the difference between the output path and the
+// expected data. renderError is an error of getting the expected data.
+// unitName is used in fails. It returns false if unable to get
+// the difference.
+func (d differ) logDiff(unitDescription string, unitName string,
+	outputPath string, expectedData []byte, renderError error) (
+	success bool) {
+	unifiedDiff, err := "", renderError
+	if err == nil {
+		unifiedDiff, err = GetUnifiedDiff(outputPath, expectedData)
+	}
+
+	if err != nil {
+		message := fmt.Sprintf("Unable to diff %v:\n%v\n%v", unitName,
+			shift(fmt.Sprintf("output: %q", outputPath), 1),
+			shift("error: "+err.Error(), 2))
+		d.logger.Fail(message)
+		return false
+	}
+
+	if unifiedDiff == "" {
+		d.logger.Log(fmt.Sprintf("%v has no changes", unitDescription))
+		return true
+	}
+
+	d.logger.Warn(fmt.Sprintf("%v differs:", unitDescription))
+	d.logger.Log(unifiedDiff)
+	return true
+}
+
+// DiffTemplates logs unified diffs between template outputs and
+// expanded templates. It returns false if any template fails.
+func (d differ) DiffTemplates(templatesToDiff []templates.Template) (
+	success bool) {
+	sort.Slice(templatesToDiff, func(i int, j int) bool {
+		return templatesToDiff[i].Name < templatesToDiff[j].Name
+	})
+
+	success = true
+	for _, template := range templatesToDiff {
+		expandedData, err := templates.Render(template)
+		unitDescription := fmt.Sprintf("Template %q", template.Name)
+		unitName := fmt.Sprintf("%q template", template.Name)
+		success = d.logDiff(unitDescription, unitName, template.OutputPath,
+			expandedData, err) && success
+	}
+
+	return success
+}
+
+// DiffCommands logs unified diffs between command outputs and outputs
+// of commands executed into temporary files. It returns false if any
+// command fails.
+func (d differ) DiffCommands(commandsToDiff []commands.Command) (
+	success bool) {
+	sort.Slice(commandsToDiff, func(i int, j int) bool {
+		return commandsToDiff[i].Name < commandsToDiff[j].Name
+	})
+
+	success = true
+	for _, command := range commandsToDiff {
+		expectedData, err := commands.Render(command)
+		unitDescription := fmt.Sprintf("Command %q output", command.Name)
+		unitName := fmt.Sprintf("%q command", command.Name)
+		success = d.logDiff(unitDescription, unitName, command.OutputPath,
+			expectedData, err) && success
+	}
+
+	return success
+}
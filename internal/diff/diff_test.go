@@ -0,0 +1,138 @@
+package diff
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/internal/deploy/commands"
+	"github.com/backdround/deploy-configs/internal/deploy/templates"
+	"github.com/backdround/deploy-configs/pkg/fstestutility"
+)
+
+func TestGetUnifiedDiff(t *testing.T) {
+	directory := t.TempDir()
+	outputPath := path.Join(directory, "output")
+
+	t.Run("OutputDoesntExist", func(t *testing.T) {
+		unifiedDiff, err := GetUnifiedDiff(outputPath, []byte("line1\n"))
+		require.NoError(t, err)
+		require.Contains(t, unifiedDiff, "--- /dev/null")
+		require.Contains(t, unifiedDiff, "+line1")
+	})
+
+	t.Run("OutputIsChanged", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("line1\nline2\n"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		unifiedDiff, err := GetUnifiedDiff(outputPath,
+			[]byte("line1\nline3\n"))
+		require.NoError(t, err)
+		require.Contains(t, unifiedDiff, " line1\n-line2\n+line3\n")
+	})
+
+	t.Run("OutputIsSame", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("line1\n"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		unifiedDiff, err := GetUnifiedDiff(outputPath, []byte("line1\n"))
+		require.NoError(t, err)
+		require.Empty(t, unifiedDiff)
+	})
+
+	t.Run("LineEndingIsMissing", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("line1"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		unifiedDiff, err := GetUnifiedDiff(outputPath, []byte("line1\n"))
+		require.NoError(t, err)
+		require.Contains(t, unifiedDiff,
+			"-line1\n\\ No newline at end of file\n+line1\n")
+	})
+
+	t.Run("OutputIsDirectory", func(t *testing.T) {
+		_, err := GetUnifiedDiff(directory, []byte("line1\n"))
+		require.Error(t, err)
+	})
+}
+
+func TestDiffTemplates(t *testing.T) {
+	directory := t.TempDir()
+	inputPath := path.Join(directory, "input")
+	outputPath := path.Join(directory, "output")
+	err := os.WriteFile(inputPath, []byte("var = {{.var}}\n"), 0644)
+	fstestutility.AssertNoError(err)
+	err = os.WriteFile(outputPath, []byte("var = 4\n"), 0644)
+	fstestutility.AssertNoError(err)
+
+	// Sets up the mock
+	logger := &LoggerMock{}
+	defer logger.AssertExpectations(t)
+	logger.On("Warn", containsString(`Template "template1" differs`)).Once()
+	logger.On("Log", containsString("-var = 4\n+var = 3")).Once()
+
+	// Executes the test
+	success := NewDiffer(logger).DiffTemplates([]templates.Template{{
+		Name:       "template1",
+		InputPath:  inputPath,
+		OutputPath: outputPath,
+		Data:       map[string]interface{}{"var": 3},
+	}})
+	require.True(t, success)
+
+	// Asserts that the output wasn't changed
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Equal(t, "var = 4\n", string(data))
+}
+
+func TestDiffCommands(t *testing.T) {
+	directory := t.TempDir()
+	inputPath := path.Join(directory, "input")
+	err := os.WriteFile(inputPath, []byte("some data\n"), 0644)
+	fstestutility.AssertNoError(err)
+
+	t.Run("OutputIsSame", func(t *testing.T) {
+		outputPath := path.Join(directory, "output")
+		err := os.WriteFile(outputPath, []byte("some data\n"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Log", containsString(`"command1" output has no changes`)).
+			Once()
+
+		// Executes the test
+		success := NewDiffer(logger).DiffCommands([]commands.Command{{
+			Name:            "command1",
+			InputPath:       inputPath,
+			OutputPath:      outputPath,
+			CommandTemplate: "cat {{.Input}} > {{.Output}}",
+		}})
+		require.True(t, success)
+	})
+
+	t.Run("CommandFails", func(t *testing.T) {
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Fail", containsString(`Unable to diff "command1" command`)).
+			Once()
+
+		// Executes the test
+		success := NewDiffer(logger).DiffCommands([]commands.Command{{
+			Name:            "command1",
+			InputPath:       inputPath,
+			OutputPath:      path.Join(directory, "output"),
+			CommandTemplate: "false",
+		}})
+		require.False(t, success)
+	})
+}
@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"strings"
+)
+
+////////////////////////////////////////////////////////////
+// LoggerMock
+
+type LoggerMock struct {
+	mock.Mock
+}
+
+func (l *LoggerMock) Warn(message string) {
+	l.Called(message)
+}
+
+func (l *LoggerMock) Fail(message string) {
+	l.Called(message)
+}
+
+func (l *LoggerMock) Log(message string) {
+	l.Called(message)
+}
+
+func getLoggerDummy() Logger {
+	logger := &LoggerMock{}
+	logger.On("Warn", mock.Anything).Maybe()
+	logger.On("Fail", mock.Anything).Maybe()
+	logger.On("Log", mock.Anything).Maybe()
+	return logger
+}
+
+////////////////////////////////////////////////////////////
+// Utility functions
+
+// containsString returns a mock.matcher that match if argument contains
+// a given string for mock.Mock.on function.
+func containsString(str string) interface{} {
+	return mock.MatchedBy(func(message string) bool {
+		return strings.Contains(message, str)
+	})
+}
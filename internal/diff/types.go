@@ -0,0 +1,7 @@
+package diff
+
+type Logger interface {
+	Warn(message string)
+	Fail(message string)
+	Log(message string)
+}
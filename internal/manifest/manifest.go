@@ -0,0 +1,325 @@
+// manifest describes a record of everything that deploy-configs created
+// for a config instance. It's updated after every run, so it's possible
+// to tell what deploy-configs owns versus what the user created by hand.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/deploy-configs/pkg/xdg"
+)
+
+const version = 1
+
+// Link represents a symbolic link created by deploy-configs
+type Link struct {
+	Name       string    `json:"name"`
+	TargetPath string    `json:"target"`
+	LinkPath   string    `json:"link"`
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+// File represents an output file of a template or a command
+type File struct {
+	Name       string    `json:"name"`
+	InputPath  string    `json:"input"`
+	OutputPath string    `json:"output"`
+	Hash       []byte    `json:"hash"`
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+// Backup represents a file that was moved away to create a link
+type Backup struct {
+	Name         string    `json:"name"`
+	OriginalPath string    `json:"original"`
+	BackupPath   string    `json:"backup"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Manifest represents all units deployed for a config instance
+type Manifest struct {
+	Version   int       `json:"version"`
+	Instance  string    `json:"instance"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Links     []Link    `json:"links"`
+	Templates []File    `json:"templates"`
+	Commands  []File    `json:"commands"`
+	// Directories are directories that were created to place units.
+	Directories []string `json:"directories"`
+	Backups     []Backup `json:"backups"`
+}
+
+// GetPath returns a path to the manifest of the given instance:
+// $XDG_STATE_HOME/deploy-configs/<instance>.json
+func GetPath(instance string) (string, error) {
+	stateHome, err := xdg.StateHome()
+	if err != nil {
+		return "", err
+	}
+
+	fileName := url.PathEscape(instance) + ".json"
+	return path.Join(stateHome, "deploy-configs", fileName), nil
+}
+
+// GetBackupDirectory returns a directory for backups of the given
+// instance that are made at the given time:
+// $XDG_STATE_HOME/deploy-configs/backups/<instance>/<time>
+func GetBackupDirectory(instance string, now time.Time) (string, error) {
+	stateHome, err := xdg.StateHome()
+	if err != nil {
+		return "", err
+	}
+
+	// Nanoseconds keep directories of runs in the same second apart
+	directoryName := now.Format("20060102-150405.000000000")
+	return path.Join(stateHome, "deploy-configs", "backups",
+		url.PathEscape(instance), directoryName), nil
+}
+
+// GetLogPath returns a path to the log file of all runs:
+// $XDG_STATE_HOME/deploy-configs/logs/deploy-configs.log
+func GetLogPath() (string, error) {
+	stateHome, err := xdg.StateHome()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(stateHome, "deploy-configs", "logs",
+		"deploy-configs.log"), nil
+}
+
+// New creates an empty manifest for the given instance.
+func New(instance string) *Manifest {
+	return &Manifest{
+		Version:     version,
+		Instance:    instance,
+		Links:       []Link{},
+		Templates:   []File{},
+		Commands:    []File{},
+		Directories: []string{},
+		Backups:     []Backup{},
+	}
+}
+
+// Load reads the manifest from the manifestPath. If the manifest
+// doesn't exist then it returns an empty manifest.
+func Load(manifestPath string, instance string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(instance), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := New(instance)
+	err = json.Unmarshal(data, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Version != version {
+		return nil, errors.New("unsupported manifest version")
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest to the manifestPath. It's written
+// atomically, because a truncated manifest loses records of backups.
+func (m *Manifest) Save(manifestPath string) error {
+	_, err := fsutility.MakeDirectoryIfDoesntExist(path.Dir(manifestPath))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fsutility.WriteFileAtomically(manifestPath, append(data, '\n'),
+		0644)
+}
+
+// AddBackup records the backup if it isn't recorded yet.
+func (m *Manifest) AddBackup(backup Backup) {
+	for _, recordedBackup := range m.Backups {
+		if recordedBackup.BackupPath == backup.BackupPath {
+			return
+		}
+	}
+	m.Backups = append(m.Backups, backup)
+}
+
+// Apply records successful outcomes to the manifest. The outcomes have
+// to cover all units of the instance. Entries of failed outcomes are kept
+// as they were. Entries of removed outcomes are deleted. Units that are
+// skipped with a reason aren't deployed, so they are ignored. Templates
+// and commands that don't have outcomes are removed from the config, so
+// their entries are deleted.
+// Backups are added even for failed outcomes, because occupying files
+// are already moved.
+func (m *Manifest) Apply(outcomes []outcome.Outcome, now time.Time) {
+	for _, o := range outcomes {
+		m.addDirectories(o.CreatedDirectories)
+		if o.Backup != "" {
+			m.AddBackup(Backup{
+				Name:         o.Name,
+				OriginalPath: o.Destination,
+				BackupPath:   o.Backup,
+				CreatedAt:    now,
+			})
+		}
+
+		if o.Action == outcome.Failed || o.Reason != "" {
+			continue
+		}
+
+		switch o.Kind {
+		case outcome.Link:
+			m.applyLink(o, now)
+		case outcome.Template:
+			m.Templates = applyFile(m.Templates, o, now)
+		case outcome.Command:
+			m.Commands = applyFile(m.Commands, o, now)
+		}
+	}
+
+	m.Templates = dropUndescribedFiles(m.Templates, outcome.Template,
+		outcomes)
+	m.Commands = dropUndescribedFiles(m.Commands, outcome.Command, outcomes)
+
+	sort.Slice(m.Links, func(i int, j int) bool {
+		return m.Links[i].LinkPath < m.Links[j].LinkPath
+	})
+	sort.Slice(m.Templates, func(i int, j int) bool {
+		return m.Templates[i].OutputPath < m.Templates[j].OutputPath
+	})
+	sort.Slice(m.Commands, func(i int, j int) bool {
+		return m.Commands[i].OutputPath < m.Commands[j].OutputPath
+	})
+
+	m.UpdatedAt = now
+}
+
+// IsEmpty checks that the manifest doesn't contain any unit, directory
+// or backup.
+func (m *Manifest) IsEmpty() bool {
+	return len(m.Links) == 0 && len(m.Templates) == 0 &&
+		len(m.Commands) == 0 && len(m.Directories) == 0 &&
+		len(m.Backups) == 0
+}
+
+func (m *Manifest) addDirectories(directories []string) {
+	for _, directory := range directories {
+		alreadyAdded := false
+		for _, addedDirectory := range m.Directories {
+			if addedDirectory == directory {
+				alreadyAdded = true
+				break
+			}
+		}
+
+		if !alreadyAdded {
+			m.Directories = append(m.Directories, directory)
+		}
+	}
+
+	sort.Strings(m.Directories)
+}
+
+func (m *Manifest) applyLink(o outcome.Outcome, now time.Time) {
+	// Skipped links are left as they are. If they aren't recorded, then
+	// they were made by hand
+	if o.Action == outcome.Skipped && !o.Disowned {
+		return
+	}
+
+	if o.Action == outcome.Removed || o.Disowned {
+		for i, link := range m.Links {
+			if link.LinkPath == o.Destination {
+				m.Links = append(m.Links[:i], m.Links[i+1:]...)
+				return
+			}
+		}
+		return
+	}
+
+	newLink := Link{
+		Name:       o.Name,
+		TargetPath: o.Source,
+		LinkPath:   o.Destination,
+		DeployedAt: now,
+	}
+
+	for i, link := range m.Links {
+		if link.LinkPath == o.Destination {
+			m.Links[i] = newLink
+			return
+		}
+	}
+
+	m.Links = append(m.Links, newLink)
+}
+
+func applyFile(files []File, o outcome.Outcome, now time.Time) []File {
+	newFile := File{
+		Name:       o.Name,
+		InputPath:  o.Source,
+		OutputPath: o.Destination,
+		Hash:       o.Hash,
+		DeployedAt: now,
+	}
+
+	for i, file := range files {
+		if file.OutputPath != o.Destination {
+			continue
+		}
+
+		// Keeps the deploy time if the file wasn't touched
+		if o.Action == outcome.Skipped && bytes.Equal(file.Hash, o.Hash) {
+			newFile.DeployedAt = file.DeployedAt
+		}
+		files[i] = newFile
+		return files
+	}
+
+	return append(files, newFile)
+}
+
+// dropUndescribedFiles returns files of the kind which units have
+// outcomes. Units with false conditions have outcomes without
+// destinations, so they are found by names.
+func dropUndescribedFiles(files []File, kind outcome.Kind,
+	outcomes []outcome.Outcome) []File {
+	destinations := map[string]bool{}
+	names := map[string]bool{}
+	for _, o := range outcomes {
+		if o.Kind != kind {
+			continue
+		}
+		if o.Destination == "" {
+			names[o.Name] = true
+			continue
+		}
+		destinations[o.Destination] = true
+	}
+
+	describedFiles := []File{}
+	for _, file := range files {
+		if destinations[file.OutputPath] || names[file.Name] {
+			describedFiles = append(describedFiles, file)
+		}
+	}
+	return describedFiles
+}
@@ -0,0 +1,301 @@
+package manifest
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/fstestutility"
+)
+
+func TestGetPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+
+	manifestPath, err := GetPath("work/pc")
+	require.NoError(t, err)
+	require.Equal(t, "/state/deploy-configs/work%2Fpc.json", manifestPath)
+}
+
+func TestGetBackupDirectory(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	directory1, err := GetBackupDirectory("pc1", now)
+	require.NoError(t, err)
+	directory2, err := GetBackupDirectory("pc1", now.Add(time.Nanosecond))
+	require.NoError(t, err)
+
+	require.Equal(t, "/state/deploy-configs/backups/pc1", path.Dir(directory1))
+	require.NotEqual(t, directory1, directory2)
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("ManifestDoesntExist", func(t *testing.T) {
+		manifestPath := fstestutility.GetAvailableTempPath()
+
+		m, err := Load(manifestPath, "pc1")
+		require.NoError(t, err)
+		require.Equal(t, New("pc1"), m)
+	})
+
+	t.Run("ManifestIsInvalid", func(t *testing.T) {
+		manifestPath, cleanup := fstestutility.CreateTemporaryFileWithData("{")
+		defer cleanup()
+
+		_, err := Load(manifestPath, "pc1")
+		require.Error(t, err)
+	})
+
+	t.Run("SavedManifest", func(t *testing.T) {
+		// Makes a manifest path inside a notexisting directory
+		directory := fstestutility.GetAvailableTempPath()
+		manifestPath := path.Join(directory, "pc1.json")
+		defer os.RemoveAll(directory)
+
+		// Saves a manifest
+		now := time.Now().UTC().Round(time.Second)
+		m := New("pc1")
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Template,
+			Name:        "template1",
+			Action:      outcome.Created,
+			Source:      "/input",
+			Destination: "/output",
+			Hash:        []byte{1, 2, 3},
+		}}, now)
+		err := m.Save(manifestPath)
+		require.NoError(t, err)
+
+		// Asserts the loaded manifest
+		loadedManifest, err := Load(manifestPath, "pc1")
+		require.NoError(t, err)
+		require.Equal(t, m, loadedManifest)
+	})
+}
+
+func TestApply(t *testing.T) {
+	deployTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := deployTime.Add(time.Hour)
+
+	getManifest := func() *Manifest {
+		m := New("pc1")
+		m.Links = []Link{{
+			Name:       "link1",
+			TargetPath: "/target1",
+			LinkPath:   "/link1",
+			DeployedAt: deployTime,
+		}}
+		m.Commands = []File{{
+			Name:       "command1",
+			InputPath:  "/input1",
+			OutputPath: "/output1",
+			Hash:       []byte{1},
+			DeployedAt: deployTime,
+		}}
+		return m
+	}
+
+	t.Run("SkippedUnitsKeepDeployTime", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link1",
+			Action:      outcome.Skipped,
+			Source:      "/target1",
+			Destination: "/link1",
+		}, {
+			Kind:        outcome.Command,
+			Name:        "command1",
+			Action:      outcome.Skipped,
+			Source:      "/input1",
+			Destination: "/output1",
+			Hash:        []byte{1},
+		}}, now)
+
+		require.Equal(t, getManifest().Links, m.Links)
+		require.Equal(t, getManifest().Commands, m.Commands)
+		require.Equal(t, now, m.UpdatedAt)
+	})
+
+	t.Run("ChangedUnitsAreReplaced", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Command,
+			Name:        "command1",
+			Action:      outcome.Replaced,
+			Source:      "/input1",
+			Destination: "/output1",
+			Hash:        []byte{2},
+		}}, now)
+
+		require.Len(t, m.Commands, 1)
+		require.Equal(t, []byte{2}, m.Commands[0].Hash)
+		require.Equal(t, now, m.Commands[0].DeployedAt)
+	})
+
+	t.Run("FailedUnitsAreIgnored", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link1",
+			Action:      outcome.Failed,
+			Source:      "/target2",
+			Destination: "/link1",
+		}, {
+			Kind:        outcome.Template,
+			Name:        "template1",
+			Action:      outcome.Failed,
+			Source:      "/input2",
+			Destination: "/output2",
+		}}, now)
+
+		require.Equal(t, getManifest().Links, m.Links)
+		require.Empty(t, m.Templates)
+	})
+
+	t.Run("CreatedDirectoriesAreAdded", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:               outcome.Link,
+			Action:             outcome.Created,
+			CreatedDirectories: []string{"/b", "/b/c"},
+		}, {
+			Kind:               outcome.Template,
+			Action:             outcome.Failed,
+			CreatedDirectories: []string{"/a"},
+		}, {
+			Kind:               outcome.Command,
+			Action:             outcome.Created,
+			CreatedDirectories: []string{"/b"},
+		}}, now)
+
+		require.Equal(t, []string{"/a", "/b", "/b/c"}, m.Directories)
+	})
+
+	t.Run("NewUnitsAreAdded", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link0",
+			Action:      outcome.Created,
+			Source:      "/target0",
+			Destination: "/link0",
+		}}, now)
+
+		require.Len(t, m.Links, 2)
+		require.Equal(t, "/link0", m.Links[0].LinkPath)
+		require.Equal(t, now, m.Links[0].DeployedAt)
+	})
+
+	t.Run("RemovedLinksAreDeleted", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link1",
+			Action:      outcome.Removed,
+			Source:      "/target1",
+			Destination: "/link1",
+		}, {
+			Kind:        outcome.Command,
+			Name:        "command1",
+			Action:      outcome.Skipped,
+			Source:      "/input1",
+			Destination: "/output1",
+			Hash:        []byte{1},
+		}}, now)
+
+		require.Empty(t, m.Links)
+		require.Equal(t, getManifest().Commands, m.Commands)
+	})
+
+	t.Run("HandMadeLinksArentRecorded", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link2",
+			Action:      outcome.Skipped,
+			Source:      "/target2",
+			Destination: "/link2",
+		}}, now)
+
+		require.Equal(t, getManifest().Links, m.Links)
+	})
+
+	t.Run("RemovedUnitsAreForgotten", func(t *testing.T) {
+		m := getManifest()
+		m.Templates = []File{{
+			Name:       "template1",
+			InputPath:  "/input2",
+			OutputPath: "/output2",
+			DeployedAt: deployTime,
+		}}
+		m.Apply([]outcome.Outcome{{
+			Kind:   outcome.Template,
+			Name:   "template1",
+			Action: outcome.Skipped,
+			Reason: "condition is false",
+		}}, now)
+
+		require.Equal(t, "template1", m.Templates[0].Name)
+		require.Empty(t, m.Commands)
+	})
+
+	t.Run("DisownedLinksAreDeleted", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link1",
+			Action:      outcome.Skipped,
+			Source:      "/target1",
+			Destination: "/link1",
+			Disowned:    true,
+		}}, now)
+
+		require.Empty(t, m.Links)
+	})
+
+	t.Run("BackupsAreAdded", func(t *testing.T) {
+		m := getManifest()
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link2",
+			Action:      outcome.Failed,
+			Source:      "/target2",
+			Destination: "/link2",
+			Backup:      "/backups/link2",
+		}}, now)
+
+		require.Equal(t, []Backup{{
+			Name:         "link2",
+			OriginalPath: "/link2",
+			BackupPath:   "/backups/link2",
+			CreatedAt:    now,
+		}}, m.Backups)
+		require.False(t, m.IsEmpty())
+	})
+
+	t.Run("RecordedBackupsArentDuplicated", func(t *testing.T) {
+		m := getManifest()
+		m.AddBackup(Backup{
+			Name:         "link2",
+			OriginalPath: "/link2",
+			BackupPath:   "/backups/link2",
+			CreatedAt:    now,
+		})
+		m.Apply([]outcome.Outcome{{
+			Kind:        outcome.Link,
+			Name:        "link2",
+			Action:      outcome.Replaced,
+			Source:      "/target2",
+			Destination: "/link2",
+			Backup:      "/backups/link2",
+		}}, now.Add(time.Second))
+
+		require.Len(t, m.Backups, 1)
+		require.Equal(t, now, m.Backups[0].CreatedAt)
+	})
+}
@@ -3,63 +3,139 @@ package pathexpander
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
 	templatePackage "text/template"
+
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/deploy-configs/pkg/xdg"
 )
 
 type Logger interface {
-	Log(message string)
+	Verbose(message string)
 	Warn(message string)
 }
 
 type PathExpander interface {
 	Expand(template string) (string, error)
+	Evaluate(condition string) (bool, error)
 }
 
 // pathexpander expands paths substitutions in given templates.
 type pathexpander struct {
-	data map[string]string
+	data map[string]interface{}
 }
 
 // New creates new pathexpander. searchGitFromDirectory is used to
-// search project git root.
-func New(l Logger, searchGitFromDirectory string) *pathexpander {
+// search project git root. configDirectory is a directory that contains
+// the config. variables are available as .Vars.
+func New(l Logger, searchGitFromDirectory string, configDirectory string,
+	variables map[string]interface{}) *pathexpander {
+	expander := pathexpander{
+		data: map[string]interface{}{
+			"Vars": variables,
+		},
+	}
+
 	log := func(message string) {
-		l.Log("path-expander: " + message)
+		l.Verbose("path-expander: " + message)
 	}
 
 	warn := func(message string) {
 		l.Warn("path-expander: " + message)
 	}
 
-	expander := pathexpander{
-		data: make(map[string]string),
+	// Adds a key if its value is gotten successfully
+	add := func(key string, value string, err error) bool {
+		if err != nil {
+			warn("Unable to get " + key)
+			return false
+		}
+
+		expander.data[key] = value
+		return true
 	}
 
-	// Adds "git-root" key
+	// Adds "GitRoot" key
 	gitRoot, err := getGitRoot(searchGitFromDirectory)
-	if err == nil {
-		expander.data["GitRoot"] = gitRoot
+	if add("GitRoot", gitRoot, err) {
 		log("GitRoot: " + gitRoot)
-	} else {
-		warn("Unable to get GitRoot")
 	}
 
-	// Adds "home" key
+	// Adds "Home" key
 	homeDirectory, err := getHomeDirectory()
-	if err == nil {
-		expander.data["Home"] = homeDirectory
+	if add("Home", homeDirectory, err) {
 		log("Home: " + homeDirectory)
-	} else {
-		warn("Unable to get Home")
 	}
 
+	// Adds "ConfigDir" key
+	if configDirectory != "" {
+		add("ConfigDir", configDirectory, nil)
+	}
+
+	// Adds XDG base directories and machine specific keys
+	configHome, err := xdg.ConfigHome()
+	add("XdgConfigHome", configHome, err)
+
+	dataHome, err := xdg.DataHome()
+	add("XdgDataHome", dataHome, err)
+
+	stateHome, err := xdg.StateHome()
+	add("XdgStateHome", stateHome, err)
+
+	cacheHome, err := xdg.CacheHome()
+	add("XdgCacheHome", cacheHome, err)
+
+	userName, err := getUserName()
+	add("User", userName, err)
+
+	hostname, err := os.Hostname()
+	add("Hostname", hostname, err)
+
+	add("Os", runtime.GOOS, nil)
+	add("Arch", runtime.GOARCH, nil)
+
 	return &expander
 }
 
-// Expand expands paths in template. It returns error if
-// template is invalid or used keys that don't exist
+// resolvePath expands a leading "~" to the home directory and resolves
+// a relative path against the config directory.
+func (expander pathexpander) resolvePath(expandedPath string) (
+	string, error) {
+	// Expands the home directory
+	if expandedPath == "~" || strings.HasPrefix(expandedPath, "~/") {
+		home, ok := expander.data["Home"].(string)
+		if !ok {
+			return "", errors.New("unable to expand \"~\" without Home")
+		}
+		expandedPath = home + strings.TrimPrefix(expandedPath, "~")
+	}
+
+	if path.IsAbs(expandedPath) {
+		return expandedPath, nil
+	}
+
+	// Resolves the relative path
+	configDirectory, ok := expander.data["ConfigDir"].(string)
+	if !ok {
+		return "", fmt.Errorf("path %q is relative, but there is no "+
+			"config directory to resolve it", expandedPath)
+	}
+
+	return path.Join(configDirectory, expandedPath), nil
+}
+
+// Expand expands paths in template. A leading "~" and relative paths
+// are resolved too. It returns error if template is invalid, used keys
+// that don't exist or the path remains relative
 func (expander pathexpander) Expand(template string) (string, error) {
 	t := templatePackage.New("path-expander").Option("missingkey=error")
+	t = t.Funcs(templatePackage.FuncMap{"env": getEnv})
 	t, err := t.Parse(template)
 	if err != nil {
 		return "", err
@@ -71,5 +147,44 @@ func (expander pathexpander) Expand(template string) (string, error) {
 		return "", err
 	}
 
-	return outputBuffer.String(), nil
+	return expander.resolvePath(outputBuffer.String())
+}
+
+// Evaluate evaluates the condition template over the same data as
+// Expand. Additionally the condition can use "exists" and "which"
+// functions. The result of the condition has to be a boolean.
+func (expander pathexpander) Evaluate(condition string) (bool, error) {
+	exists := func(pathToCheck string) (bool, error) {
+		resolvedPath, err := expander.resolvePath(pathToCheck)
+		if err != nil {
+			return false, err
+		}
+		pathType := fsutility.GetPathType(resolvedPath)
+		return pathType != fsutility.Notexisting, nil
+	}
+
+	t := templatePackage.New("condition").Option("missingkey=error")
+	t = t.Funcs(templatePackage.FuncMap{
+		"env":    os.Getenv,
+		"exists": exists,
+		"which":  isExecutableFound,
+	})
+	t, err := t.Parse(condition)
+	if err != nil {
+		return false, err
+	}
+
+	outputBuffer := bytes.NewBuffer([]byte{})
+	err = t.Execute(outputBuffer, expander.data)
+	if err != nil {
+		return false, err
+	}
+
+	result := strings.TrimSpace(outputBuffer.String())
+	value, err := strconv.ParseBool(result)
+	if err != nil {
+		return false, fmt.Errorf("condition result %q isn't a boolean", result)
+	}
+
+	return value, nil
 }
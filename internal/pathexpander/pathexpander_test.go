@@ -3,6 +3,7 @@ package pathexpander
 import (
 	"os"
 	"path"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -15,7 +16,7 @@ type LoggerMock struct {
 	mock.Mock
 }
 
-func (l *LoggerMock) Log(message string) {
+func (l *LoggerMock) Verbose(message string) {
 	l.Called(message)
 }
 
@@ -25,7 +26,7 @@ func (l *LoggerMock) Warn(message string) {
 
 func getLoggerDummy() *LoggerMock {
 	logger := &LoggerMock{}
-	logger.On("Log", mock.Anything).Maybe()
+	logger.On("Verbose", mock.Anything).Maybe()
 	logger.On("Warn", mock.Anything).Maybe()
 	return logger
 }
@@ -46,11 +47,11 @@ func TestExpandWithoutGitRoot(t *testing.T) {
 	// Creates a logger
 	logger := &LoggerMock{}
 	logger.On("Warn", containsString("GitRoot")).Once()
-	logger.On("Log", containsString("Home")).Once()
+	logger.On("Verbose", containsString("Home")).Once()
 	defer logger.AssertExpectations(t)
 
 	// Executes the test
-	expander := New(logger, os.TempDir())
+	expander := New(logger, os.TempDir(), "", nil)
 	_, err1 := expander.Expand(path1)
 	_, err2 := expander.Expand(path2)
 
@@ -61,7 +62,7 @@ func TestExpandWithoutGitRoot(t *testing.T) {
 
 func TestExpandInvalidTemplate(t *testing.T) {
 	// Executes the test
-	expander := New(getLoggerDummy(), os.TempDir())
+	expander := New(getLoggerDummy(), os.TempDir(), "", nil)
 	_, err1 := expander.Expand("{{Home}}")
 
 	// Asserts expansions
@@ -84,12 +85,12 @@ func TestExpandWithGitRoot(t *testing.T) {
 
 	// Creates a logger
 	logger := &LoggerMock{}
-	logger.On("Log", containsString("GitRoot")).Once()
-	logger.On("Log", containsString("Home")).Once()
+	logger.On("Verbose", containsString("GitRoot")).Once()
+	logger.On("Verbose", containsString("Home")).Once()
 	defer logger.AssertExpectations(t)
 
 	// Executes the test
-	expander := New(logger, testRootDirectory)
+	expander := New(logger, testRootDirectory, "", nil)
 	path1, err1 := expander.Expand(path1)
 	_, err2 := expander.Expand(path2)
 
@@ -98,3 +99,97 @@ func TestExpandWithGitRoot(t *testing.T) {
 	require.Equal(t, testRootDirectory+"/configs/file1", path1)
 	require.NoError(t, err2)
 }
+
+func TestExpandVariables(t *testing.T) {
+	variables := map[string]interface{}{
+		"configRoot": "/etc/configs",
+	}
+
+	// Executes the test
+	expander := New(getLoggerDummy(), os.TempDir(), "", variables)
+	path1, err1 := expander.Expand("{{.Vars.configRoot}}/file1")
+	_, err2 := expander.Expand("{{.Vars.unknown}}/file1")
+
+	// Asserts expansions
+	require.NoError(t, err1)
+	require.Equal(t, "/etc/configs/file1", path1)
+	require.Error(t, err2)
+}
+
+func TestExpandMachineData(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/some/config")
+	t.Setenv("DEPLOY_CONFIGS_TEST_VARIABLE", "value")
+
+	// Executes the test
+	expander := New(getLoggerDummy(), os.TempDir(), "/configs", nil)
+	expand := func(template string) string {
+		expandedPath, err := expander.Expand(template)
+		require.NoError(t, err)
+		return expandedPath
+	}
+
+	// Asserts expansions
+	require.Equal(t, "/configs/file1", expand("{{.ConfigDir}}/file1"))
+	require.Equal(t, "/some/config/file1", expand("{{.XdgConfigHome}}/file1"))
+	require.Equal(t, "/"+runtime.GOOS+"-"+runtime.GOARCH, expand("/{{.Os}}-{{.Arch}}"))
+	require.Equal(t, "/value", expand(`/{{env "DEPLOY_CONFIGS_TEST_VARIABLE"}}`))
+	require.NotEqual(t, "/", expand("/{{.User}}"))
+	require.NotEqual(t, "/", expand("/{{.Hostname}}"))
+
+	_, err := expander.Expand(`{{env "DEPLOY_CONFIGS_UNSET_VARIABLE"}}`)
+	require.Error(t, err)
+}
+
+func TestResolvePath(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+
+	t.Run("WithConfigDirectory", func(t *testing.T) {
+		expander := New(getLoggerDummy(), os.TempDir(), "/configs", nil)
+		expand := func(template string) string {
+			expandedPath, err := expander.Expand(template)
+			require.NoError(t, err)
+			return expandedPath
+		}
+
+		require.Equal(t, "/home/user/file1", expand("~/file1"))
+		require.Equal(t, "/home/user", expand("~"))
+		require.Equal(t, "/configs/file1", expand("./file1"))
+		require.Equal(t, "/file1", expand("../file1"))
+		require.Equal(t, "/configs/~file1", expand("~file1"))
+		require.Equal(t, "/etc/file1", expand("/etc/file1"))
+	})
+
+	t.Run("WithoutConfigDirectory", func(t *testing.T) {
+		expander := New(getLoggerDummy(), os.TempDir(), "", nil)
+		_, err := expander.Expand("./file1")
+		require.ErrorContains(t, err, "relative")
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	directory := t.TempDir()
+	t.Setenv("DEPLOY_CONFIGS_TEST_VARIABLE", "value")
+	variables := map[string]interface{}{
+		"enabled": true,
+	}
+	expander := New(getLoggerDummy(), os.TempDir(), directory, variables)
+
+	evaluate := func(condition string) bool {
+		result, err := expander.Evaluate(condition)
+		require.NoError(t, err)
+		return result
+	}
+
+	require.True(t, evaluate("true"))
+	require.True(t, evaluate(`{{eq .Os "`+runtime.GOOS+`"}}`))
+	require.True(t, evaluate("{{.Vars.enabled}}"))
+	require.True(t, evaluate(`{{eq (env "DEPLOY_CONFIGS_TEST_VARIABLE") "value"}}`))
+	require.False(t, evaluate(`{{eq (env "DEPLOY_CONFIGS_UNSET_VARIABLE") "value"}}`))
+	require.True(t, evaluate(`{{exists "."}}`))
+	require.False(t, evaluate(`{{exists "./missing"}}`))
+	require.True(t, evaluate(`{{which "sh"}}`))
+	require.False(t, evaluate(`{{which "deploy-configs-missing-program"}}`))
+
+	_, err := expander.Evaluate("maybe")
+	require.ErrorContains(t, err, "isn't a boolean")
+}
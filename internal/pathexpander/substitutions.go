@@ -2,7 +2,9 @@ package pathexpander
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"os/user"
 	"path"
 
@@ -41,3 +43,35 @@ func getHomeDirectory() (string, error) {
 
 	return "", err
 }
+
+// getUserName returns the name of the current user
+func getUserName() (string, error) {
+	user, err := user.Current()
+	if err == nil {
+		return user.Username, nil
+	}
+
+	userName := os.Getenv("USER")
+	if userName != "" {
+		return userName, nil
+	}
+
+	return "", err
+}
+
+// getEnv returns a value of the environment variable. It's used as
+// the "env" template function.
+func getEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q isn't set", name)
+	}
+	return value, nil
+}
+
+// isExecutableFound checks that the executable is found in PATH. It's
+// used as the "which" condition function.
+func isExecutableFound(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,150 @@
+package realmain
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// Commands that are available from the command line
+const (
+	deployCommand   = "deploy"
+	undeployCommand = "undeploy"
+	statusCommand   = "status"
+	diffCommand     = "diff"
+	restoreCommand  = "restore"
+	listCommand     = "list"
+)
+
+// Output formats that are available from the command line
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+)
+
+// isCommand checks that the argument is a command name.
+func isCommand(argument string) bool {
+	switch argument {
+	case deployCommand, undeployCommand, statusCommand, diffCommand,
+		restoreCommand, listCommand:
+		return true
+	}
+	return false
+}
+
+// arguments represents parsed command line arguments.
+type arguments struct {
+	command     string
+	instances   []string
+	configPath  string
+	output      string
+	colorMode   logger.ColorMode
+	level       logger.Level
+	logFile     bool
+	interactive bool
+	dryRun      bool
+	prune       bool
+	backup      bool
+	force       bool
+}
+
+// parseArguments parses cliArguments. Flags are allowed to be placed
+// before and after positional arguments.
+func parseArguments(cliArguments []string) (*arguments, error) {
+	args := &arguments{}
+
+	flags := flag.NewFlagSet(path.Base(cliArguments[0]), flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&args.configPath, "config", "",
+		"path to the config instead of searching it")
+	flags.StringVar(&args.output, "output", textOutput,
+		"output format: text or json")
+	colorMode := flags.String("color", string(logger.ColorAuto),
+		"when to color messages: auto, always or never")
+	quiet := flags.Bool("q", false, "log only failures")
+	verbose := flags.Bool("v", false,
+		"log also skipped units, expanded paths and executed commands")
+	debug := flags.Bool("vv", false, "log also outputs of executed commands")
+	flags.BoolVar(&args.logFile, "log-file", false,
+		"append the full log of the run to the log file")
+	flags.BoolVar(&args.interactive, "interactive", false,
+		"ask before replacing links and overwriting outputs")
+	flags.BoolVar(&args.dryRun, "dry-run", false,
+		"log planned changes without touching the filesystem")
+	flags.BoolVar(&args.prune, "prune", false,
+		"remove stale links that point into the git root")
+	flags.BoolVar(&args.backup, "backup", false,
+		"move files that occupy link paths to the backup directory")
+	flags.BoolVar(&args.force, "force", false,
+		"overwrite outputs that were modified after deploying")
+
+	// Parses flags that are interleaved with positional arguments
+	positional := []string{}
+	rest := cliArguments[1:]
+	for len(rest) != 0 {
+		err := flags.Parse(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		// Treats everything after "--" as positional arguments
+		parsedCount := len(rest) - flags.NArg()
+		if parsedCount > 0 && rest[parsedCount-1] == "--" {
+			positional = append(positional, flags.Args()...)
+			break
+		}
+
+		rest = flags.Args()
+		if len(rest) != 0 {
+			positional = append(positional, rest[0])
+			rest = rest[1:]
+		}
+	}
+
+	// Gets the log level
+	if *quiet && (*verbose || *debug) {
+		return nil, errors.New("-q can't be used with -v or -vv")
+	}
+	switch {
+	case *quiet:
+		args.level = logger.LevelQuiet
+	case *debug:
+		args.level = logger.LevelDebug
+	case *verbose:
+		args.level = logger.LevelVerbose
+	default:
+		args.level = logger.LevelNormal
+	}
+
+	if args.output != textOutput && args.output != jsonOutput {
+		return nil, fmt.Errorf("unknown output format %q", args.output)
+	}
+
+	args.colorMode = logger.ColorMode(*colorMode)
+	switch args.colorMode {
+	case logger.ColorAuto, logger.ColorAlways, logger.ColorNever:
+	default:
+		return nil, fmt.Errorf("unknown color mode %q", *colorMode)
+	}
+
+	if args.interactive && args.output == jsonOutput {
+		return nil, errors.New("--interactive can't be used with --output json")
+	}
+
+	// Gets the command. Instances named as commands have to be given
+	// after the command, e.g. "deploy status".
+	args.command = deployCommand
+	if len(positional) != 0 && isCommand(positional[0]) {
+		args.command = positional[0]
+		positional = positional[1:]
+	}
+
+	// Instances are selected automatically if they aren't given
+	args.instances = positional
+
+	return args, nil
+}
@@ -0,0 +1,87 @@
+package realmain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/internal/diff"
+	"github.com/mattn/go-isatty"
+)
+
+// terminalConfirmer asks the user at the terminal whether destructive
+// actions are allowed. Answers "all" and "quit" are applied to all
+// following actions.
+type terminalConfirmer struct {
+	input  *bufio.Reader
+	output io.Writer
+	all    bool
+	quit   bool
+}
+
+// isInteractive checks that there is a user at the terminal to answer
+// questions.
+func isInteractive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) ||
+		isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
+
+// newConfirmer returns a confirmer if the run is interactive.
+// Otherwise all actions are allowed without asking.
+func newConfirmer(args *arguments) outcome.Confirmer {
+	if !args.interactive {
+		return nil
+	}
+
+	return &terminalConfirmer{
+		input:  bufio.NewReader(os.Stdin),
+		output: os.Stdout,
+	}
+}
+
+// Confirm shows the action with the difference of the output path and
+// asks the user until a valid answer is given.
+func (c *terminalConfirmer) Confirm(description string, outputPath string,
+	newData []byte) bool {
+	if c.quit {
+		return false
+	}
+	if c.all {
+		return true
+	}
+
+	fmt.Fprintln(c.output, description)
+	if newData != nil {
+		unifiedDiff, err := diff.GetUnifiedDiff(outputPath, newData)
+		if err == nil && unifiedDiff != "" {
+			fmt.Fprint(c.output, unifiedDiff)
+		}
+	}
+
+	for {
+		fmt.Fprint(c.output, "Proceed? [y]es, [n]o, [a]ll, [q]uit: ")
+		answer, err := c.input.ReadString('\n')
+		if err != nil {
+			// The input is closed, so nobody is able to answer
+			fmt.Fprintln(c.output)
+			c.quit = true
+			return false
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "a", "all":
+			c.all = true
+			return true
+		case "q", "quit":
+			c.quit = true
+			return false
+		}
+	}
+}
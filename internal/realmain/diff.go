@@ -0,0 +1,32 @@
+package realmain
+
+import (
+	"github.com/backdround/deploy-configs/internal/diff"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// diffInstance logs unified diffs between outputs of the config
+// instance and expanded templates and commands without changing
+// anything.
+func diffInstance(l logger.Logger, args *arguments,
+	configInstance string) int {
+	instance, ok := readInstance(l, args, configInstance)
+	if !ok {
+		return 1
+	}
+
+	returnCode := 0
+	differ := diff.NewDiffer(l)
+
+	l.Title("Diff templates")
+	if !differ.DiffTemplates(instance.templates) {
+		returnCode = 1
+	}
+
+	l.Title("Diff commands")
+	if !differ.DiffCommands(instance.commands) {
+		returnCode = 1
+	}
+
+	return returnCode
+}
@@ -0,0 +1,106 @@
+package realmain
+
+import (
+	"path"
+	"sort"
+
+	"github.com/backdround/deploy-configs/internal/config"
+	"github.com/backdround/deploy-configs/internal/dataconverter"
+	"github.com/backdround/deploy-configs/internal/deploy/commands"
+	"github.com/backdround/deploy-configs/internal/deploy/links"
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/internal/deploy/templates"
+	"github.com/backdround/deploy-configs/internal/pathexpander"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// instanceData represents deploy data of a config instance.
+type instanceData struct {
+	name      string
+	links     []links.Link
+	templates []templates.Template
+	commands  []commands.Command
+	// disabled are skipped outcomes of units which conditions are false.
+	disabled     []outcome.Outcome
+	pathExpander pathexpander.PathExpander
+}
+
+// readInstance searches the config, parses it and restructures the
+// config instance to deploy data. It logs all errors.
+func readInstance(l logger.Logger, args *arguments,
+	configInstance string) (instance *instanceData, ok bool) {
+	// Searches config path
+	configPath, err := getConfigPath(args)
+	if err != nil {
+		l.Fail("Error occurs while config searching:")
+		l.Fail(err.Error())
+		return nil, false
+	}
+
+	// Reads config yaml
+	configData, err := config.Read(configPath)
+	if err != nil {
+		l.Fail("Unable to read config data:")
+		l.Fail(err.Error())
+		return nil, false
+	}
+
+	// Parse config data
+	config, err := config.Get(configData, configInstance)
+	if err != nil {
+		l.Fail("Fail to parse config data:")
+		l.Fail(err.Error())
+		return nil, false
+	}
+
+	// Restructures config to deploy data
+	configDirectory := path.Dir(configPath)
+	pathExpander := pathexpander.New(l, configDirectory, configDirectory,
+		config.Variables)
+	disabled := &outcome.Collector{}
+	dataConverter := dataconverter.New(l, pathExpander, config.Variables,
+		disabled)
+
+	restructuredLinks, err := dataConverter.RestructureLinks(config.Links)
+	if err != nil {
+		l.Fail("Invalid config links:")
+		l.Fail(err.Error())
+		return nil, false
+	}
+
+	restructuredTemplates, err := dataConverter.RestructureTemplates(
+		config.Templates)
+	if err != nil {
+		l.Fail("Invalid config templates:")
+		l.Fail(err.Error())
+		return nil, false
+	}
+
+	restructuredCommands, err := dataConverter.RestructureCommands(
+		config.Commands)
+	if err != nil {
+		l.Fail("Invalid config commands:")
+		l.Fail(err.Error())
+		return nil, false
+	}
+
+	// Sorts disabled units, because config units aren't ordered
+	sort.Slice(disabled.Outcomes, func(i int, j int) bool {
+		a, b := disabled.Outcomes[i], disabled.Outcomes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	instance = &instanceData{
+		name:         configInstance,
+		links:        restructuredLinks,
+		templates:    restructuredTemplates,
+		commands:     restructuredCommands,
+		disabled:     disabled.Outcomes,
+		pathExpander: pathExpander,
+	}
+
+	return instance, true
+}
@@ -0,0 +1,165 @@
+package realmain
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/backdround/deploy-configs/internal/deploy/links"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// instanceLogger prefixes titles with the instance name, so logs
+// of several instances are distinguishable.
+type instanceLogger struct {
+	logger.Logger
+	instance string
+}
+
+func (l instanceLogger) Title(title string) {
+	l.Logger.Title(fmt.Sprintf("%v: %v", l.instance, title))
+}
+
+// getInstanceLogger returns a logger for the instance. Titles are
+// prefixed only if there are several instances.
+func getInstanceLogger(l logger.Logger, args *arguments,
+	instance string) logger.Logger {
+	if len(args.instances) == 1 {
+		return l
+	}
+	return instanceLogger{Logger: l, instance: instance}
+}
+
+// forEachInstance performs the action for every instance from
+// the arguments. It returns a non-zero code if any action fails.
+func forEachInstance(l logger.Logger, args *arguments,
+	action func(l logger.Logger, args *arguments, instance string) int) int {
+	returnCode := 0
+	for _, instance := range args.instances {
+		instanceLogger := getInstanceLogger(l, args, instance)
+		if action(instanceLogger, args, instance) != 0 {
+			returnCode = 1
+		}
+	}
+
+	return returnCode
+}
+
+// outputOwner is a unit of an instance which creates an output path.
+type outputOwner struct {
+	instance    string
+	description string
+	// unit identifies the unit by its kind, name and content. Instances
+	// which inherit the same unit produce the same output.
+	unit string
+}
+
+// findConflicts returns descriptions of paths that are outputs of
+// different units from different instances. The same inherited unit is
+// deployed by several instances without conflicts.
+func findConflicts(instances []*instanceData) []string {
+	owners := map[string][]outputOwner{}
+	addOwner := func(outputPath string, instance string, owner string,
+		content ...interface{}) {
+		outputPath = path.Clean(outputPath)
+		owners[outputPath] = append(owners[outputPath], outputOwner{
+			instance:    instance,
+			description: fmt.Sprintf("%v %v", instance, owner),
+			unit:        fmt.Sprintf("%v %#v", owner, content),
+		})
+	}
+
+	for _, instance := range instances {
+		// Uses the same links as linkMaker creates. Unreadable directories
+		// are reported while deploying.
+		expandedLinks, err := links.ExpandLinks(instance.links)
+		if err != nil {
+			expandedLinks = instance.links
+		}
+
+		for _, link := range expandedLinks {
+			addOwner(link.LinkPath, instance.name,
+				fmt.Sprintf("link %q", link.Name), link.TargetPath)
+		}
+		for _, template := range instance.templates {
+			addOwner(template.OutputPath, instance.name,
+				fmt.Sprintf("template %q", template.Name), template.InputPath,
+				template.Data)
+		}
+		for _, command := range instance.commands {
+			addOwner(command.OutputPath, instance.name,
+				fmt.Sprintf("command %q", command.Name), command.InputPath,
+				command.CommandTemplate)
+		}
+	}
+
+	conflicts := []string{}
+	for outputPath, pathOwners := range owners {
+		instanceNames := map[string]bool{}
+		units := map[string]bool{}
+		for _, owner := range pathOwners {
+			instanceNames[owner.instance] = true
+			units[owner.unit] = true
+		}
+		if len(instanceNames) < 2 || len(units) < 2 {
+			continue
+		}
+
+		descriptions := []string{}
+		for _, owner := range pathOwners {
+			descriptions = append(descriptions, owner.description)
+		}
+		sort.Strings(descriptions)
+		conflict := fmt.Sprintf("%q:\n  %v", outputPath,
+			strings.Join(descriptions, "\n  "))
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Strings(conflicts)
+
+	return conflicts
+}
+
+// deployInstances deploys all instances from the arguments one by one.
+// Nothing is deployed if the instances have conflicting outputs. It
+// returns outcomes of deployed instances.
+func deployInstances(l logger.Logger, args *arguments) (
+	int, []instanceOutcomes) {
+	instances := []*instanceData{}
+	for _, configInstance := range args.instances {
+		instance, ok := readInstance(l, args, configInstance)
+		if !ok {
+			return 1, nil
+		}
+		instances = append(instances, instance)
+	}
+
+	// Checks that instances don't overwrite each other
+	conflicts := findConflicts(instances)
+	if len(conflicts) != 0 {
+		l.Fail("Instances have conflicting outputs:")
+		for _, conflict := range conflicts {
+			l.Fail(conflict)
+		}
+		return 1, nil
+	}
+
+	returnCode := 0
+	deployed := []instanceOutcomes{}
+	confirmer := newConfirmer(args)
+	for _, instance := range instances {
+		instanceLogger := getInstanceLogger(l, args, instance.name)
+		instanceReturnCode, outcomes := deployInstance(instanceLogger, args,
+			instance, confirmer)
+		if instanceReturnCode != 0 {
+			returnCode = 1
+		}
+
+		deployed = append(deployed, instanceOutcomes{
+			instance: instance.name,
+			outcomes: outcomes,
+		})
+	}
+
+	return returnCode, deployed
+}
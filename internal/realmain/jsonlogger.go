@@ -0,0 +1,172 @@
+package realmain
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// instanceOutcomes represents outcomes of a deployed instance.
+type instanceOutcomes struct {
+	instance string
+	outcomes []outcome.Outcome
+}
+
+// messageEvent represents a logged message.
+type messageEvent struct {
+	Type    string `json:"type"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// unitEvent represents an outcome of a deployed unit.
+type unitEvent struct {
+	Type        string         `json:"type"`
+	Instance    string         `json:"instance"`
+	Kind        outcome.Kind   `json:"kind"`
+	Name        string         `json:"name"`
+	Action      outcome.Action `json:"action"`
+	Source      string         `json:"source,omitempty"`
+	Destination string         `json:"destination,omitempty"`
+	Command     string         `json:"command,omitempty"`
+	Backup      string         `json:"backup,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	// Duration is in seconds.
+	Duration float64 `json:"duration"`
+}
+
+// summaryEvent represents a result of the whole run.
+type summaryEvent struct {
+	Type       string                 `json:"type"`
+	Command    string                 `json:"command"`
+	Instances  []string               `json:"instances"`
+	Actions    map[outcome.Action]int `json:"actions"`
+	ReturnCode int                    `json:"return_code"`
+}
+
+// jsonLogger is a logger.Logger that emits every message as a json
+// event to the output logger. One event takes exactly one line.
+type jsonLogger struct {
+	output logger.Logger
+	// resultOutput receives unit and summary events besides the output,
+	// e.g. the log file. It can be nil.
+	resultOutput logger.Logger
+}
+
+func newJsonLogger(output logger.Logger) *jsonLogger {
+	return &jsonLogger{
+		output: output,
+	}
+}
+
+func (l jsonLogger) emit(event interface{}) {
+	l.emitTo(l.output, event)
+}
+
+// emitResult emits the event to the output and the result output.
+func (l jsonLogger) emitResult(event interface{}) {
+	output := l.output
+	if l.resultOutput != nil {
+		output = logger.Fanout(l.output, l.resultOutput)
+	}
+	l.emitTo(output, event)
+}
+
+func (l jsonLogger) emitTo(output logger.Logger, event interface{}) {
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(event)
+	if err != nil {
+		output.Fail("Unable to encode json event: " + err.Error())
+		return
+	}
+
+	output.Log(strings.TrimSuffix(data.String(), "\n"))
+}
+
+func (l jsonLogger) message(level string, message string) {
+	l.emit(messageEvent{
+		Type:    "message",
+		Level:   level,
+		Message: message,
+	})
+}
+
+func (l jsonLogger) Title(title string) {
+	l.message("title", title)
+}
+
+func (l jsonLogger) Success(message string) {
+	l.message("success", message)
+}
+
+func (l jsonLogger) Warn(message string) {
+	l.message("warn", message)
+}
+
+func (l jsonLogger) Fail(message string) {
+	l.message("fail", message)
+}
+
+func (l jsonLogger) Log(message string) {
+	l.message("log", message)
+}
+
+func (l jsonLogger) Skip(message string) {
+	l.message("skip", message)
+}
+
+func (l jsonLogger) Verbose(message string) {
+	l.message("verbose", message)
+}
+
+func (l jsonLogger) Debug(message string) {
+	l.message("debug", message)
+}
+
+// Units emits an event for every outcome of the instances.
+func (l jsonLogger) Units(deployed []instanceOutcomes) {
+	for _, instance := range deployed {
+		for _, o := range instance.outcomes {
+			l.emitResult(unitEvent{
+				Type:        "unit",
+				Instance:    instance.instance,
+				Kind:        o.Kind,
+				Name:        o.Name,
+				Action:      o.Action,
+				Source:      o.Source,
+				Destination: o.Destination,
+				Command:     o.Command,
+				Backup:      o.Backup,
+				Reason:      o.Reason,
+				Error:       o.Error,
+				Duration:    o.Duration.Seconds(),
+			})
+		}
+	}
+}
+
+// Summary emits the final event with counts of unit actions.
+func (l jsonLogger) Summary(args *arguments, deployed []instanceOutcomes,
+	returnCode int) {
+	actions := map[outcome.Action]int{}
+	for _, instance := range deployed {
+		for _, o := range instance.outcomes {
+			actions[o.Action]++
+		}
+	}
+
+	l.emitResult(summaryEvent{
+		Type:       "summary",
+		Command:    args.command,
+		Instances:  args.instances,
+		Actions:    actions,
+		ReturnCode: returnCode,
+	})
+}
@@ -0,0 +1,171 @@
+package realmain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/backdround/deploy-configs/internal/config"
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// listedUnit represents a unit of an instance in the list output.
+type listedUnit struct {
+	Instance string       `json:"instance"`
+	Kind     outcome.Kind `json:"kind"`
+	Name     string       `json:"name"`
+	// Source is a link target or an input path.
+	Source string `json:"source"`
+	// Destination is a link path or an output path.
+	Destination string `json:"destination"`
+	Command     string `json:"command,omitempty"`
+}
+
+// failLogger passes only fail messages, so the list output isn't mixed
+// with other messages.
+type failLogger struct {
+	logger.Logger
+}
+
+func (l failLogger) Title(title string)     {}
+func (l failLogger) Success(message string) {}
+func (l failLogger) Warn(message string)    {}
+func (l failLogger) Log(message string)     {}
+func (l failLogger) Skip(message string)    {}
+func (l failLogger) Verbose(message string) {}
+func (l failLogger) Debug(message string)   {}
+
+// logJson logs the value as indented json.
+func logJson(l logger.Logger, value interface{}) int {
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(value)
+	if err != nil {
+		l.Fail("Unable to encode json:")
+		l.Fail(err.Error())
+		return 1
+	}
+
+	l.Log(strings.TrimSuffix(data.String(), "\n"))
+	return 0
+}
+
+// listInstances logs names of all instances of the config.
+func listInstances(l logger.Logger, args *arguments) int {
+	configPath, err := getConfigPath(args)
+	if err != nil {
+		l.Fail("Error occurs while config searching:")
+		l.Fail(err.Error())
+		return 1
+	}
+
+	configData, err := config.Read(configPath)
+	if err != nil {
+		l.Fail("Unable to read config data:")
+		l.Fail(err.Error())
+		return 1
+	}
+
+	instances, err := config.GetInstances(configData)
+	if err != nil {
+		l.Fail("Fail to parse config data:")
+		l.Fail(err.Error())
+		return 1
+	}
+
+	if args.output == jsonOutput {
+		return logJson(l, instances)
+	}
+
+	for _, instance := range instances {
+		l.Log(instance)
+	}
+	return 0
+}
+
+// sortUnits sorts the units by their names.
+func sortUnits(units []listedUnit) []listedUnit {
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Name < units[j].Name
+	})
+	return units
+}
+
+// getListedUnits returns all units of the instance with expanded paths.
+// Links go first, then templates and commands. Every kind is sorted by
+// names.
+func getListedUnits(instance *instanceData) []listedUnit {
+	linkUnits := []listedUnit{}
+	for _, link := range instance.links {
+		linkUnits = append(linkUnits, listedUnit{
+			Instance:    instance.name,
+			Kind:        outcome.Link,
+			Name:        link.Name,
+			Source:      link.TargetPath,
+			Destination: link.LinkPath,
+		})
+	}
+
+	templateUnits := []listedUnit{}
+	for _, template := range instance.templates {
+		templateUnits = append(templateUnits, listedUnit{
+			Instance:    instance.name,
+			Kind:        outcome.Template,
+			Name:        template.Name,
+			Source:      template.InputPath,
+			Destination: template.OutputPath,
+		})
+	}
+
+	commandUnits := []listedUnit{}
+	for _, command := range instance.commands {
+		commandUnits = append(commandUnits, listedUnit{
+			Instance:    instance.name,
+			Kind:        outcome.Command,
+			Name:        command.Name,
+			Source:      command.InputPath,
+			Destination: command.OutputPath,
+			Command:     command.CommandTemplate,
+		})
+	}
+
+	units := sortUnits(linkUnits)
+	units = append(units, sortUnits(templateUnits)...)
+	return append(units, sortUnits(commandUnits)...)
+}
+
+// listUnits logs units of the instances from the arguments as a table
+// or json.
+func listUnits(l logger.Logger, args *arguments) int {
+	units := []listedUnit{}
+	for _, configInstance := range args.instances {
+		instance, ok := readInstance(failLogger{l}, args, configInstance)
+		if !ok {
+			return 1
+		}
+		units = append(units, getListedUnits(instance)...)
+	}
+
+	if args.output == jsonOutput {
+		return logJson(l, units)
+	}
+
+	table := &bytes.Buffer{}
+	writer := tabwriter.NewWriter(table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "INSTANCE\tKIND\tNAME\tSOURCE\tDESTINATION")
+	for _, unit := range units {
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", unit.Instance, unit.Kind,
+			unit.Name, unit.Source, unit.Destination)
+	}
+	writer.Flush()
+
+	l.Log(strings.TrimSuffix(table.String(), "\n"))
+	return 0
+}
@@ -0,0 +1,51 @@
+package realmain
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/backdround/deploy-configs/internal/manifest"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// Limits of the log file. The file is rotated when it reaches
+// logFileMaxSize and logFileBackupCount old files are kept.
+const (
+	logFileMaxSize     = 1024 * 1024
+	logFileBackupCount = 5
+)
+
+// logFile is an opened log file with a logger that writes to it.
+type logFile struct {
+	file   *os.File
+	logger logger.Logger
+}
+
+// openLogFile opens the log file and starts the run log with the
+// command line.
+func openLogFile(cliArguments []string) (*logFile, error) {
+	logPath, err := manifest.GetLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := logger.OpenFile(logPath, logFileMaxSize, logFileBackupCount)
+	if err != nil {
+		return nil, err
+	}
+
+	fileLogger := logger.NewFileLogger(file)
+	commandLine := append([]string{path.Base(cliArguments[0])},
+		cliArguments[1:]...)
+	fileLogger.Title("Run: " + strings.Join(commandLine, " "))
+
+	return &logFile{
+		file:   file,
+		logger: fileLogger,
+	}, nil
+}
+
+func (f *logFile) Close() error {
+	return f.file.Close()
+}
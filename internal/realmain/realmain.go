@@ -3,15 +3,19 @@ package realmain
 import (
 	"errors"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/backdround/deploy-configs/internal/config"
-	"github.com/backdround/deploy-configs/internal/dataconverter"
 	"github.com/backdround/deploy-configs/internal/deploy/commands"
 	"github.com/backdround/deploy-configs/internal/deploy/links"
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
 	"github.com/backdround/deploy-configs/internal/deploy/templates"
-	"github.com/backdround/deploy-configs/internal/pathexpander"
+	"github.com/backdround/deploy-configs/internal/manifest"
 	"github.com/backdround/deploy-configs/pkg/fsutility"
 	"github.com/backdround/deploy-configs/pkg/logger"
+	"github.com/backdround/deploy-configs/pkg/xdg"
 )
 
 func FindConfig(cwd string, names ...string) (configPath string, err error) {
@@ -26,100 +30,347 @@ func FindConfig(cwd string, names ...string) (configPath string, err error) {
 	return "", errors.New("unable to find config path")
 }
 
-func Main(l logger.Logger, cliArguments []string) int {
-	// Gets config instance
-	userInput := cliArguments[1:]
-	if len(userInput) != 1 {
-		l.Fail("Expected config instance as argument")
-		return 1
+// getConfigPath returns the config path that is given by the command
+// line or the environment. Otherwise it searches the config from the
+// current work directory up to the root and then in the user config
+// directory. Symbolic links are resolved to get the real config
+// directory.
+func getConfigPath(args *arguments) (string, error) {
+	configPath := args.configPath
+	if configPath == "" {
+		configPath = os.Getenv("DEPLOY_CONFIGS_FILE")
+	}
+
+	if configPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+
+		configPath, err = FindConfig(cwd, "deploy-configs.yml",
+			"deploy-configs.yaml")
+		if err != nil {
+			configPath, err = getUserConfigPath()
+		}
+		if err != nil {
+			return "", err
+		}
 	}
-	configInstance := userInput[0]
 
-	// Gets cwd
-	cwd, err := os.Getwd()
+	configPath, err := filepath.Abs(configPath)
 	if err != nil {
-		l.Fail("Unable to get current work directory:")
-		l.Fail(err.Error())
-		return 1
+		return "", err
 	}
 
-	// Searches config path
-	configPath, err := FindConfig(cwd, "deploy-configs.yml",
-		"deploy-configs.yaml")
+	return filepath.EvalSymlinks(configPath)
+}
+
+// getUserConfigPath returns the config path from the user config
+// directory if it exists.
+func getUserConfigPath() (string, error) {
+	configHome, err := xdg.ConfigHome()
 	if err != nil {
-		l.Fail("Error occurs while config searching:")
-		l.Fail(err.Error())
-		return 1
+		return "", err
+	}
+
+	configPath := path.Join(configHome, "deploy-configs", "config.yaml")
+	pathType := fsutility.GetPathType(configPath)
+	if pathType != fsutility.Regular && pathType != fsutility.Symlink {
+		return "", errors.New("unable to find config path")
 	}
 
-	// Reads config yaml
-	configData, err := os.ReadFile(configPath)
+	return configPath, nil
+}
+
+// loadManifest loads the manifest of the instance. If the instance
+// isn't deployed then it returns an empty manifest.
+func loadManifest(instance string) (*manifest.Manifest, error) {
+	manifestPath, err := manifest.GetPath(instance)
 	if err != nil {
-		l.Fail("Unable to read config data:")
-		l.Fail(err.Error())
-		return 1
+		return nil, err
+	}
+
+	return manifest.Load(manifestPath, instance)
+}
+
+// getDeployedLinks returns links that are recorded in the manifest.
+func getDeployedLinks(m *manifest.Manifest) []links.Link {
+	deployedLinks := []links.Link{}
+	for _, link := range m.Links {
+		deployedLinks = append(deployedLinks, links.Link{
+			Name:       link.Name,
+			TargetPath: link.TargetPath,
+			LinkPath:   link.LinkPath,
+		})
 	}
 
-	// Parse config data
-	config, err := config.Get(configData, configInstance)
+	return deployedLinks
+}
+
+// getDeployedHashes returns hashes of the deployed files by their
+// output paths.
+func getDeployedHashes(files []manifest.File) map[string][]byte {
+	deployedHashes := map[string][]byte{}
+	for _, file := range files {
+		deployedHashes[file.OutputPath] = file.Hash
+	}
+
+	return deployedHashes
+}
+
+// updateManifest records the outcomes to the manifest of the instance.
+func updateManifest(instance string, outcomes []outcome.Outcome) error {
+	manifestPath, err := manifest.GetPath(instance)
 	if err != nil {
-		l.Fail("Fail to parse config data:")
-		l.Fail(err.Error())
-		return 1
+		return err
+	}
+
+	m, err := manifest.Load(manifestPath, instance)
+	if err != nil {
+		return err
 	}
 
-	// Restructures config to deploy data
-	pathExpander := pathexpander.New(l, cwd)
-	dataConverter := dataconverter.New(l, pathExpander)
+	m.Apply(outcomes, time.Now())
+	return m.Save(manifestPath)
+}
 
-	restructuredLinks, err := dataConverter.RestructureLinks(config.Links)
+// manifestBackupRecorder saves every backup to the instance manifest
+// as soon as it's made.
+type manifestBackupRecorder struct {
+	instance string
+}
+
+func (r manifestBackupRecorder) RecordBackup(name string,
+	originalPath string, backupPath string) error {
+	manifestPath, err := manifest.GetPath(r.instance)
 	if err != nil {
-		l.Fail("Invalid config links:")
-		l.Fail(err.Error())
-		return 1
+		return err
 	}
 
-	restructuredTemplates, err := dataConverter.RestructureTemplates(
-		config.Templates)
+	m, err := manifest.Load(manifestPath, r.instance)
 	if err != nil {
-		l.Fail("Invalid config templates:")
+		return err
+	}
+
+	m.AddBackup(manifest.Backup{
+		Name:         name,
+		OriginalPath: originalPath,
+		BackupPath:   backupPath,
+		CreatedAt:    time.Now(),
+	})
+	return m.Save(manifestPath)
+}
+
+func Main(l logger.Logger, cliArguments []string) int {
+	// Parses command line arguments
+	args, err := parseArguments(cliArguments)
+	if err != nil {
+		l.Fail("Invalid arguments:")
 		l.Fail(err.Error())
 		return 1
 	}
 
-	restructuredCommands, err := dataConverter.RestructureCommands(
-		config.Commands)
+	// Refuses to wait for answers that nobody is able to give
+	if args.interactive && !isInteractive() {
+		l.Fail("Invalid arguments:")
+		l.Fail("--interactive requires a terminal")
+		return 1
+	}
+
+	// Sets up colors of the terminal logger
+	if colorModeSetter, ok := l.(logger.ColorModeSetter); ok {
+		colorModeSetter.SetColorMode(args.colorMode)
+	}
+
+	// Emits json events instead of messages. The list command prints
+	// json by itself.
+	var jsonL *jsonLogger
+	if args.output == jsonOutput && args.command != listCommand {
+		jsonL = newJsonLogger(l)
+		l = jsonL
+	}
+
+	// Filters messages by the log level
+	l = logger.WithLevel(l, args.level)
+
+	// Appends all messages to the log file
+	if args.logFile {
+		logFile, err := openLogFile(cliArguments)
+		if err != nil {
+			l.Fail("Unable to open log file:")
+			l.Fail(err.Error())
+			return 1
+		}
+		defer logFile.Close()
+
+		l = logger.Fanout(l, logFile.logger)
+
+		// Messages reach the log file as they are, but results are
+		// emitted only as json events
+		if jsonL != nil {
+			jsonL.resultOutput = logFile.logger
+		}
+	}
+
+	// Lists instances of the config
+	if args.command == listCommand && len(args.instances) == 0 {
+		return listInstances(l, args)
+	}
+
+	startTime := time.Now()
+	returnCode, deployed := run(l, args)
+
+	if jsonL != nil {
+		jsonL.Units(deployed)
+		jsonL.Summary(args, deployed, returnCode)
+	} else if len(deployed) != 0 {
+		logSummary(l, deployed, time.Since(startTime))
+	}
+
+	return returnCode
+}
+
+// run selects instances if they aren't given and performs the command.
+// It returns outcomes of deployed instances.
+func run(l logger.Logger, args *arguments) (int, []instanceOutcomes) {
+	// Selects instances that match this machine
+	if len(args.instances) == 0 {
+		var err error
+		args.instances, err = selectInstances(args)
+		if err != nil {
+			l.Fail("Expected config instance as argument, because " +
+				"unable to select it automatically:")
+			l.Fail(err.Error())
+			return 1, nil
+		}
+
+		message := "Selected instances: " + strings.Join(args.instances, ", ")
+		l.Success(message)
+	}
+
+	switch args.command {
+	case undeployCommand:
+		return forEachInstance(l, args, undeployInstance), nil
+	case statusCommand:
+		return forEachInstance(l, args, checkInstance), nil
+	case diffCommand:
+		return forEachInstance(l, args, diffInstance), nil
+	case restoreCommand:
+		return forEachInstance(l, args, restoreInstance), nil
+	case listCommand:
+		return listUnits(l, args), nil
+	default:
+		return deployInstances(l, args)
+	}
+}
+
+// deployInstance deploys the config instance. It returns outcomes of
+// deployed units.
+func deployInstance(l logger.Logger, args *arguments,
+	instance *instanceData, confirmer outcome.Confirmer) (
+	int, []outcome.Outcome) {
+	configInstance := instance.name
+
+	// Reads previously deployed units
+	deployedManifest, err := loadManifest(configInstance)
 	if err != nil {
-		l.Fail("Invalid config commands:")
+		l.Fail("Unable to read deployment manifest:")
 		l.Fail(err.Error())
-		return 1
+		return 1, nil
+	}
+
+	// Gets data to prune stale links
+	var gitRoot string
+	if args.prune {
+		gitRoot, err = instance.pathExpander.Expand("{{.GitRoot}}")
+		if err != nil {
+			l.Fail("Unable to prune links without GitRoot:")
+			l.Fail(err.Error())
+			return 1, nil
+		}
 	}
 
 	returnCode := 0
+	outcomes := &outcome.Collector{}
+	for _, disabled := range instance.disabled {
+		outcomes.Record(disabled)
+	}
+
+	if args.dryRun {
+		l.Warn("Dry run: the filesystem isn't going to be changed")
+	}
+
+	// Gets a directory for backups of occupying files
+	backupDirectory, err := manifest.GetBackupDirectory(configInstance,
+		time.Now())
+	if err != nil {
+		l.Fail("Unable to get backup directory:")
+		l.Fail(err.Error())
+		return 1, nil
+	}
 
 	// Deploys links
-	linkMaker := links.NewLinkMaker(l)
+	linkMaker := links.NewLinkMaker(l, links.Options{
+		DryRun:          args.dryRun,
+		Recorder:        outcomes,
+		Confirmer:       confirmer,
+		Backup:          args.backup,
+		BackupDirectory: backupDirectory,
+		BackupRecorder:  manifestBackupRecorder{instance: configInstance},
+	})
 	l.Title("Create links")
-	success := linkMaker.CreateLinks(restructuredLinks)
+	success := linkMaker.CreateLinks(instance.links)
 	if !success {
 		returnCode = 1
 	}
 
+	// Prunes stale links
+	if args.prune {
+		l.Title("Prune links")
+		success = linkMaker.PruneLinks(instance.links,
+			getDeployedLinks(deployedManifest), gitRoot)
+		if !success {
+			returnCode = 1
+		}
+	}
+
 	// Deploys templates
-	templateMaker := templates.NewTemplateMaker(l)
+	templateMaker := templates.NewTemplateMaker(l, templates.Options{
+		DryRun:         args.dryRun,
+		Recorder:       outcomes,
+		Confirmer:      confirmer,
+		DeployedHashes: getDeployedHashes(deployedManifest.Templates),
+		Force:          args.force,
+	})
 	l.Title("Make templates")
-	success = templateMaker.MakeTemplates(restructuredTemplates)
+	success = templateMaker.MakeTemplates(instance.templates)
 	if !success {
 		returnCode = 1
 	}
 
 	// Deploys commands
-	commandExecuter := commands.NewCommandExecuter(l)
+	commandExecuter := commands.NewCommandExecuter(l, commands.Options{
+		DryRun:         args.dryRun,
+		Recorder:       outcomes,
+		Confirmer:      confirmer,
+		DeployedHashes: getDeployedHashes(deployedManifest.Commands),
+		Force:          args.force,
+	})
 	l.Title("Execute commands")
-	success = commandExecuter.ExecuteCommands(restructuredCommands)
+	success = commandExecuter.ExecuteCommands(instance.commands)
 	if !success {
 		returnCode = 1
 	}
 
-	return returnCode
+	// Records deployed units to the instance manifest
+	if !args.dryRun {
+		err := updateManifest(configInstance, outcomes.Outcomes)
+		if err != nil {
+			l.Fail("Unable to update deployment manifest:")
+			l.Fail(err.Error())
+			returnCode = 1
+		}
+	}
+
+	return returnCode, outcomes.Outcomes
 }
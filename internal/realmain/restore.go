@@ -0,0 +1,40 @@
+package realmain
+
+import (
+	"github.com/backdround/deploy-configs/internal/undeploy"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// restoreInstance moves files that were backed up while deploying
+// the config instance back to their original paths.
+func restoreInstance(l logger.Logger, args *arguments,
+	configInstance string) int {
+	m, manifestPath, returnCode := readDeployedManifest(l, configInstance)
+	if m == nil {
+		return returnCode
+	}
+
+	if args.dryRun {
+		l.Warn("Dry run: the filesystem isn't going to be changed")
+	}
+
+	// Restores backups
+	undeployer := undeploy.NewUndeployer(l,
+		undeploy.Options{DryRun: args.dryRun})
+
+	l.Title("Restore backups")
+	if !undeployer.RestoreBackups(m) {
+		returnCode = 1
+	}
+
+	if args.dryRun {
+		return returnCode
+	}
+
+	// Updates the instance manifest
+	if !saveDeployedManifest(l, m, manifestPath) {
+		returnCode = 1
+	}
+
+	return returnCode
+}
@@ -0,0 +1,48 @@
+package realmain
+
+import (
+	"os"
+	"os/user"
+	"runtime"
+
+	"github.com/backdround/deploy-configs/internal/config"
+)
+
+// getMachine returns a description of the current machine which is
+// used to select instances.
+func getMachine() config.Machine {
+	machine := config.Machine{
+		Os:        runtime.GOOS,
+		LookupEnv: os.LookupEnv,
+	}
+
+	hostname, err := os.Hostname()
+	if err == nil {
+		machine.Hostname = hostname
+	}
+
+	currentUser, err := user.Current()
+	if err == nil {
+		machine.User = currentUser.Username
+	} else {
+		machine.User = os.Getenv("USER")
+	}
+
+	return machine
+}
+
+// selectInstances returns instances from the config which selectors
+// match the current machine.
+func selectInstances(args *arguments) ([]string, error) {
+	configPath, err := getConfigPath(args)
+	if err != nil {
+		return nil, err
+	}
+
+	configData, err := config.Read(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.SelectInstances(configData, getMachine())
+}
@@ -0,0 +1,50 @@
+package realmain
+
+import (
+	"github.com/backdround/deploy-configs/internal/status"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// checkInstance logs drift between the config instance and the
+// filesystem without changing anything. It returns a non-zero code
+// if the instance isn't in sync.
+func checkInstance(l logger.Logger, args *arguments,
+	configInstance string) int {
+	instance, ok := readInstance(l, args, configInstance)
+	if !ok {
+		return 1
+	}
+
+	deployedManifest, err := loadManifest(configInstance)
+	if err != nil {
+		l.Fail("Unable to read deployment manifest:")
+		l.Fail(err.Error())
+		return 1
+	}
+	deployedHashes := getDeployedHashes(deployedManifest.Templates)
+	for outputPath, hash := range getDeployedHashes(deployedManifest.Commands) {
+		deployedHashes[outputPath] = hash
+	}
+
+	returnCode := 0
+	statusChecker := status.NewStatusChecker(l, status.Options{
+		DeployedHashes: deployedHashes,
+	})
+
+	l.Title("Check links")
+	if !statusChecker.CheckLinks(instance.links) {
+		returnCode = 1
+	}
+
+	l.Title("Check templates")
+	if !statusChecker.CheckTemplates(instance.templates) {
+		returnCode = 1
+	}
+
+	l.Title("Check commands")
+	if !statusChecker.CheckCommands(instance.commands) {
+		returnCode = 1
+	}
+
+	return returnCode
+}
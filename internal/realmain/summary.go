@@ -0,0 +1,86 @@
+package realmain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/backdround/deploy-configs/internal/deploy/outcome"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// summaryKinds are kinds of units in the order of the summary rows.
+var summaryKinds = []struct {
+	kind  outcome.Kind
+	title string
+}{
+	{outcome.Link, "links"},
+	{outcome.Template, "templates"},
+	{outcome.Command, "commands"},
+}
+
+// summaryActions are actions in the order of the summary columns.
+var summaryActions = []outcome.Action{
+	outcome.Created,
+	outcome.Replaced,
+	outcome.Skipped,
+	outcome.Failed,
+	outcome.Removed,
+}
+
+// logSummary logs a table with counts of unit actions by kinds and
+// the total time. Failed units are listed after the table, so they
+// don't get lost among other messages.
+func logSummary(l logger.Logger, deployed []instanceOutcomes,
+	duration time.Duration) {
+	counts := map[outcome.Kind]map[outcome.Action]int{}
+	failed := []string{}
+	for _, instance := range deployed {
+		for _, o := range instance.outcomes {
+			if counts[o.Kind] == nil {
+				counts[o.Kind] = map[outcome.Action]int{}
+			}
+			counts[o.Kind][o.Action]++
+
+			if o.Action == outcome.Failed {
+				failed = append(failed, fmt.Sprintf("%v: %v %q", instance.instance,
+					o.Kind, o.Name))
+			}
+		}
+	}
+
+	// Makes the table
+	table := &bytes.Buffer{}
+	writer := tabwriter.NewWriter(table, 0, 0, 2, ' ', 0)
+	header := []string{""}
+	for _, action := range summaryActions {
+		header = append(header, string(action))
+	}
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+
+	for _, kind := range summaryKinds {
+		row := []string{kind.title}
+		for _, action := range summaryActions {
+			row = append(row, fmt.Sprint(counts[kind.kind][action]))
+		}
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	writer.Flush()
+
+	fmt.Fprintf(table, "Total time: %v", duration.Round(time.Millisecond))
+
+	// Logs the summary
+	l.Title("Summary")
+	if len(failed) == 0 {
+		l.Success(table.String())
+		return
+	}
+
+	l.Fail(table.String())
+	l.Fail("Failed units:")
+	for _, unit := range failed {
+		l.Fail("  " + unit)
+	}
+}
@@ -0,0 +1,105 @@
+package realmain
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/backdround/deploy-configs/internal/manifest"
+	"github.com/backdround/deploy-configs/internal/undeploy"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/deploy-configs/pkg/logger"
+)
+
+// readDeployedManifest reads the manifest of the config instance.
+// If the instance isn't deployed or the manifest is unreadable then
+// it returns nil manifest and the return code of the application.
+func readDeployedManifest(l logger.Logger, instance string) (
+	m *manifest.Manifest, manifestPath string, returnCode int) {
+	manifestPath, err := manifest.GetPath(instance)
+	if err != nil {
+		l.Fail("Unable to get deployment manifest path:")
+		l.Fail(err.Error())
+		return nil, "", 1
+	}
+
+	if fsutility.GetPathType(manifestPath) == fsutility.Notexisting {
+		message := fmt.Sprintf("Instance %q isn't deployed", instance)
+		l.Warn(message)
+		return nil, "", 0
+	}
+
+	m, err = manifest.Load(manifestPath, instance)
+	if err != nil {
+		l.Fail("Unable to read deployment manifest:")
+		l.Fail(err.Error())
+		return nil, "", 1
+	}
+
+	return m, manifestPath, 0
+}
+
+// saveDeployedManifest saves the manifest or removes it if it's empty.
+func saveDeployedManifest(l logger.Logger, m *manifest.Manifest,
+	manifestPath string) (success bool) {
+	var err error
+	if m.IsEmpty() {
+		err = os.Remove(manifestPath)
+	} else {
+		err = m.Save(manifestPath)
+	}
+
+	if err != nil {
+		l.Fail("Unable to update deployment manifest:")
+		l.Fail(err.Error())
+		return false
+	}
+
+	return true
+}
+
+// undeployInstance removes everything that is recorded in the manifest
+// of the config instance.
+func undeployInstance(l logger.Logger, args *arguments,
+	configInstance string) int {
+	m, manifestPath, returnCode := readDeployedManifest(l, configInstance)
+	if m == nil {
+		return returnCode
+	}
+
+	if args.dryRun {
+		l.Warn("Dry run: the filesystem isn't going to be changed")
+	}
+
+	// Removes deployed units
+	undeployer := undeploy.NewUndeployer(l,
+		undeploy.Options{DryRun: args.dryRun})
+
+	l.Title("Remove links")
+	if !undeployer.RemoveLinks(m) {
+		returnCode = 1
+	}
+
+	l.Title("Remove templates")
+	if !undeployer.RemoveTemplates(m) {
+		returnCode = 1
+	}
+
+	l.Title("Remove commands")
+	if !undeployer.RemoveCommands(m) {
+		returnCode = 1
+	}
+
+	l.Title("Remove directories")
+	undeployer.RemoveDirectories(m)
+
+	if args.dryRun {
+		return returnCode
+	}
+
+	// Updates the instance manifest
+	if !saveDeployedManifest(l, m, manifestPath) {
+		returnCode = 1
+	}
+
+	return returnCode
+}
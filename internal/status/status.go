@@ -0,0 +1,183 @@
+// status describes statusChecker which compares links, templates and
+// commands with the live filesystem without changing anything. It logs
+// a state of every unit.
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/backdround/deploy-configs/internal/deploy/commands"
+	"github.com/backdround/deploy-configs/internal/deploy/links"
+	"github.com/backdround/deploy-configs/internal/deploy/templates"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/go-indent"
+)
+
+type statusChecker struct {
+	logger  Logger
+	options Options
+}
+
+func NewStatusChecker(logger Logger, options Options) statusChecker {
+	return statusChecker{
+		logger:  logger,
+		options: options,
+	}
+}
+
+func shift(message string, count int) string {
+	return indent.Indent(message, "  ", count)
+}
+
+// logState logs the state of the unit. It returns true if the unit
+// is in sync.
+func (c statusChecker) logState(unitDescription string, description string,
+	state State) (inSync bool) {
+	if state == InSync {
+		c.logger.Log(fmt.Sprintf("%v is %v", unitDescription, state))
+		return true
+	}
+
+	message := fmt.Sprintf("%v is %v:\n%v", unitDescription, state,
+		shift(description, 1))
+	c.logger.Warn(message)
+	return false
+}
+
+func (c statusChecker) logFail(unitDescription string, description string,
+	err error) {
+	message := fmt.Sprintf("Unable to check %v:\n%v\n%v", unitDescription,
+		shift(description, 1), shift("error: "+err.Error(), 2))
+	c.logger.Fail(message)
+}
+
+// getLinkState returns the state of the link.
+func getLinkState(link links.Link) State {
+	switch fsutility.GetPathType(link.LinkPath) {
+	case fsutility.Notexisting:
+		return Missing
+	case fsutility.Symlink:
+		if fsutility.IsLinkPointsToDestination(link.LinkPath, link.TargetPath) {
+			return InSync
+		}
+		return PointsElsewhere
+	}
+
+	return Occupied
+}
+
+// getFileState returns the state of the output file that is
+// expected to contain the expectedData. deployedHash is a hash of the
+// output file that was deployed last time. It can be nil.
+func getFileState(outputPath string, expectedData []byte,
+	deployedHash []byte) State {
+	switch fsutility.GetPathType(outputPath) {
+	case fsutility.Notexisting:
+		return Missing
+	case fsutility.Regular:
+		outputHash := fsutility.GetFileHash(outputPath)
+		if bytes.Equal(outputHash, fsutility.GetHash(expectedData)) {
+			return InSync
+		}
+		if deployedHash != nil && bytes.Equal(outputHash, deployedHash) {
+			return Outdated
+		}
+		return Modified
+	}
+
+	return Occupied
+}
+
+// CheckLinks logs states of the links. Links which targets are
+// directories are checked for every entry. It returns true if all
+// links are in sync.
+func (c statusChecker) CheckLinks(linksToCheck []links.Link) (inSync bool) {
+	sort.Slice(linksToCheck, func(i int, j int) bool {
+		return linksToCheck[i].Name < linksToCheck[j].Name
+	})
+
+	inSync = true
+	for _, link := range linksToCheck {
+		specificLinks, err := links.ExpandLinks([]links.Link{link})
+		if err != nil {
+			description := fmt.Sprintf("target: %q\nlink: %q",
+				link.TargetPath, link.LinkPath)
+			c.logFail(fmt.Sprintf("%q link", link.Name), description, err)
+			inSync = false
+			continue
+		}
+
+		for _, specificLink := range specificLinks {
+			description := fmt.Sprintf("target: %q\nlink: %q",
+				specificLink.TargetPath, specificLink.LinkPath)
+			unitDescription := fmt.Sprintf("Link %q", specificLink.Name)
+			state := getLinkState(specificLink)
+			inSync = c.logState(unitDescription, description, state) && inSync
+		}
+	}
+
+	return inSync
+}
+
+// CheckTemplates logs states of the template outputs. It returns true
+// if all template outputs are in sync.
+func (c statusChecker) CheckTemplates(
+	templatesToCheck []templates.Template) (inSync bool) {
+	sort.Slice(templatesToCheck, func(i int, j int) bool {
+		return templatesToCheck[i].Name < templatesToCheck[j].Name
+	})
+
+	inSync = true
+	for _, template := range templatesToCheck {
+		description := fmt.Sprintf("input: %q\noutput: %q",
+			template.InputPath, template.OutputPath)
+
+		expandedData, err := templates.Render(template)
+		if err != nil {
+			c.logFail(fmt.Sprintf("%q template", template.Name), description,
+				err)
+			inSync = false
+			continue
+		}
+
+		unitDescription := fmt.Sprintf("Template %q", template.Name)
+		state := getFileState(template.OutputPath, expandedData,
+			c.options.DeployedHashes[template.OutputPath])
+		inSync = c.logState(unitDescription, description, state) && inSync
+	}
+
+	return inSync
+}
+
+// CheckCommands logs states of the command outputs. Commands are
+// executed into temporary files to get expected outputs. It returns
+// true if all command outputs are in sync.
+func (c statusChecker) CheckCommands(
+	commandsToCheck []commands.Command) (inSync bool) {
+	sort.Slice(commandsToCheck, func(i int, j int) bool {
+		return commandsToCheck[i].Name < commandsToCheck[j].Name
+	})
+
+	inSync = true
+	for _, command := range commandsToCheck {
+		description := fmt.Sprintf("input: %q\noutput: %q",
+			command.InputPath, command.OutputPath)
+
+		expectedData, err := commands.Render(command)
+		if err != nil {
+			c.logFail(fmt.Sprintf("%q command", command.Name), description,
+				err)
+			inSync = false
+			continue
+		}
+
+		unitDescription := fmt.Sprintf("Command %q output", command.Name)
+		state := getFileState(command.OutputPath, expectedData,
+			c.options.DeployedHashes[command.OutputPath])
+		inSync = c.logState(unitDescription, description, state) && inSync
+	}
+
+	return inSync
+}
@@ -0,0 +1,215 @@
+package status
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/internal/deploy/commands"
+	"github.com/backdround/deploy-configs/internal/deploy/links"
+	"github.com/backdround/deploy-configs/internal/deploy/templates"
+	"github.com/backdround/deploy-configs/pkg/fstestutility"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+func TestGetLinkState(t *testing.T) {
+	directory := t.TempDir()
+	linkPath := path.Join(directory, "link")
+	link := links.Link{
+		Name:       "link1",
+		TargetPath: "/dev/null",
+		LinkPath:   linkPath,
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		require.Equal(t, Missing, getLinkState(link))
+	})
+
+	t.Run("InSync", func(t *testing.T) {
+		fstestutility.AssertNoError(os.Symlink("/dev/null", linkPath))
+		defer os.Remove(linkPath)
+		require.Equal(t, InSync, getLinkState(link))
+	})
+
+	t.Run("PointsElsewhere", func(t *testing.T) {
+		fstestutility.AssertNoError(os.Symlink("/dev/zero", linkPath))
+		defer os.Remove(linkPath)
+		require.Equal(t, PointsElsewhere, getLinkState(link))
+	})
+
+	t.Run("Occupied", func(t *testing.T) {
+		fstestutility.AssertNoError(os.WriteFile(linkPath, nil, 0644))
+		defer os.Remove(linkPath)
+		require.Equal(t, Occupied, getLinkState(link))
+	})
+}
+
+func TestGetFileState(t *testing.T) {
+	directory := t.TempDir()
+	outputPath := path.Join(directory, "output")
+	data := []byte("some data")
+
+	t.Run("Missing", func(t *testing.T) {
+		require.Equal(t, Missing, getFileState(outputPath, data, nil))
+	})
+
+	t.Run("InSync", func(t *testing.T) {
+		fstestutility.AssertNoError(os.WriteFile(outputPath, data, 0644))
+		defer os.Remove(outputPath)
+		require.Equal(t, InSync, getFileState(outputPath, data, nil))
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("other data"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+		require.Equal(t, Modified, getFileState(outputPath, data, nil))
+	})
+
+	t.Run("ModifiedAfterDeploying", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("other data"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+		deployedHash := fsutility.GetHash([]byte("deployed data"))
+		require.Equal(t, Modified, getFileState(outputPath, data,
+			deployedHash))
+	})
+
+	t.Run("Outdated", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("deployed data"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+		deployedHash := fsutility.GetHash([]byte("deployed data"))
+		require.Equal(t, Outdated, getFileState(outputPath, data,
+			deployedHash))
+	})
+
+	t.Run("Occupied", func(t *testing.T) {
+		fstestutility.AssertNoError(os.Mkdir(outputPath, 0755))
+		defer os.Remove(outputPath)
+		require.Equal(t, Occupied, getFileState(outputPath, data, nil))
+	})
+}
+
+func TestCheckLinks(t *testing.T) {
+	directory := t.TempDir()
+	inSyncLinkPath := path.Join(directory, "in-sync")
+	fstestutility.AssertNoError(os.Symlink("/dev/null", inSyncLinkPath))
+
+	// Sets up the mock
+	logger := &LoggerMock{}
+	defer logger.AssertExpectations(t)
+	logger.On("Log", containsString(`Link "link1" is in sync`)).Once()
+	logger.On("Warn", containsString(`Link "link2" is missing`)).Once()
+
+	// Executes the test
+	inSync := NewStatusChecker(logger, Options{}).CheckLinks([]links.Link{{
+		Name:       "link1",
+		TargetPath: "/dev/null",
+		LinkPath:   inSyncLinkPath,
+	}, {
+		Name:       "link2",
+		TargetPath: "/dev/null",
+		LinkPath:   path.Join(directory, "missing"),
+	}})
+
+	require.False(t, inSync)
+}
+
+func TestCheckTemplates(t *testing.T) {
+	directory := t.TempDir()
+	inputPath := path.Join(directory, "input")
+	outputPath := path.Join(directory, "output")
+	err := os.WriteFile(inputPath, []byte("var = {{.var}}"), 0644)
+	fstestutility.AssertNoError(err)
+
+	template := templates.Template{
+		Name:       "template1",
+		InputPath:  inputPath,
+		OutputPath: outputPath,
+		Data:       map[string]interface{}{"var": 3},
+	}
+
+	t.Run("InSync", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("var = 3"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Log", containsString(`"template1" is in sync`)).Once()
+
+		// Executes the test
+		checker := NewStatusChecker(logger, Options{})
+		inSync := checker.CheckTemplates([]templates.Template{template})
+		require.True(t, inSync)
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("var = 4"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Warn", containsString(`"template1" is locally modified`)).
+			Once()
+
+		// Executes the test
+		checker := NewStatusChecker(logger, Options{})
+		inSync := checker.CheckTemplates([]templates.Template{template})
+		require.False(t, inSync)
+	})
+}
+
+func TestCheckCommands(t *testing.T) {
+	directory := t.TempDir()
+	inputPath := path.Join(directory, "input")
+	outputPath := path.Join(directory, "output")
+	err := os.WriteFile(inputPath, []byte("some data"), 0644)
+	fstestutility.AssertNoError(err)
+
+	command := commands.Command{
+		Name:            "command1",
+		InputPath:       inputPath,
+		OutputPath:      outputPath,
+		CommandTemplate: "cat {{.Input}} > {{.Output}}",
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Warn", containsString(`"command1" output is missing`)).
+			Once()
+
+		// Executes the test
+		checker := NewStatusChecker(logger, Options{})
+		inSync := checker.CheckCommands([]commands.Command{command})
+		require.False(t, inSync)
+
+		// Asserts that the output wasn't created
+		require.NoFileExists(t, outputPath)
+	})
+
+	t.Run("InSync", func(t *testing.T) {
+		err := os.WriteFile(outputPath, []byte("some data"), 0644)
+		fstestutility.AssertNoError(err)
+		defer os.Remove(outputPath)
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Log", containsString(`"command1" output is in sync`)).
+			Once()
+
+		// Executes the test
+		checker := NewStatusChecker(logger, Options{})
+		inSync := checker.CheckCommands([]commands.Command{command})
+		require.True(t, inSync)
+	})
+}
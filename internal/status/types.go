@@ -0,0 +1,36 @@
+package status
+
+// State represents how a deployed unit relates to its description
+type State string
+
+const (
+	InSync State = "in sync"
+	// Missing means that the link or the output file doesn't exist.
+	Missing State = "missing"
+	// Occupied means that a different kind of file is placed instead
+	// of the link or the output file.
+	Occupied State = "occupied"
+	// PointsElsewhere means that the link points to a different target.
+	PointsElsewhere State = "pointing elsewhere"
+	// Modified means that the output file was changed after deploying.
+	// Without a deployed hash it means that the output file differs
+	// from the expanded one.
+	Modified State = "locally modified"
+	// Outdated means that the output file is left as it was deployed,
+	// but its sources are changed since then.
+	Outdated State = "outdated"
+)
+
+// Options adjusts the behaviour of statusChecker.
+type Options struct {
+	// DeployedHashes are hashes of template and command outputs by their
+	// paths which were deployed last time. They are used to tell local
+	// modifications from changed sources. It's optional.
+	DeployedHashes map[string][]byte
+}
+
+type Logger interface {
+	Warn(message string)
+	Fail(message string)
+	Log(message string)
+}
@@ -0,0 +1,122 @@
+package undeploy
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/backdround/deploy-configs/internal/manifest"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+// restoreBackup moves the backup to its original path. A link that
+// occupies the original path is removed only if it still points to
+// the deployedTarget. It returns false if the backup has to be kept in
+// the manifest.
+func (u undeployer) restoreBackup(backup manifest.Backup,
+	deployedTarget string) (restored bool, err error) {
+	unitDescription := fmt.Sprintf("Backup of %q link", backup.Name)
+	description := shift(fmt.Sprintf("original: %q\nbackup: %q",
+		backup.OriginalPath, backup.BackupPath), 1)
+
+	if fsutility.GetPathType(backup.BackupPath) == fsutility.Notexisting {
+		message := fmt.Sprintf("%v is missing:\n%v", unitDescription,
+			description)
+		u.logger.Warn(message)
+		return true, nil
+	}
+
+	originalType := fsutility.GetPathType(backup.OriginalPath)
+	if originalType != fsutility.Notexisting &&
+		originalType != fsutility.Symlink {
+		message := fmt.Sprintf("%v is left, because its original path "+
+			"is occupied:\n%v", unitDescription, description)
+		u.logger.Warn(message)
+		return false, nil
+	}
+
+	if originalType == fsutility.Symlink && (deployedTarget == "" ||
+		!fsutility.IsLinkPointsToDestination(backup.OriginalPath,
+			deployedTarget)) {
+		message := fmt.Sprintf("%v is left, because the link on its "+
+			"original path was changed after deploying:\n%v",
+			unitDescription, description)
+		u.logger.Warn(message)
+		return false, nil
+	}
+
+	if u.options.DryRun {
+		message := fmt.Sprintf("%v would be restored:\n%v", unitDescription,
+			description)
+		u.logger.Success(message)
+		return true, nil
+	}
+
+	err = func() error {
+		if originalType == fsutility.Symlink {
+			err := os.Remove(backup.OriginalPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		directory := path.Dir(backup.OriginalPath)
+		_, err := fsutility.MakeDirectoryIfDoesntExist(directory)
+		if err != nil {
+			return err
+		}
+
+		return os.Rename(backup.BackupPath, backup.OriginalPath)
+	}()
+
+	if err != nil {
+		errorMessage := shift("error: "+err.Error(), 2)
+		message := fmt.Sprintf("Unable to restore %v:\n%v\n%v",
+			unitDescription, description, errorMessage)
+		u.logger.Fail(message)
+		return false, err
+	}
+
+	message := fmt.Sprintf("%v restored:\n%v", unitDescription, description)
+	u.logger.Success(message)
+	return true, nil
+}
+
+// RestoreBackups moves all backups of the manifest back to their
+// original paths. The latest backups are restored first. Restored
+// backups and links which they replace are excluded from the manifest.
+func (u undeployer) RestoreBackups(m *manifest.Manifest) (success bool) {
+	success = true
+	leftBackups := []manifest.Backup{}
+	for i := len(m.Backups) - 1; i >= 0; i-- {
+		backup := m.Backups[i]
+
+		deployedTarget := ""
+		for _, link := range m.Links {
+			if link.LinkPath == backup.OriginalPath {
+				deployedTarget = link.TargetPath
+			}
+		}
+
+		restored, err := u.restoreBackup(backup, deployedTarget)
+		if err != nil {
+			success = false
+		}
+		if !restored {
+			leftBackups = append([]manifest.Backup{backup}, leftBackups...)
+			continue
+		}
+
+		// Excludes the replaced link from the manifest
+		leftLinks := []manifest.Link{}
+		for _, link := range m.Links {
+			if link.LinkPath != backup.OriginalPath {
+				leftLinks = append(leftLinks, link)
+			}
+		}
+		m.Links = leftLinks
+	}
+
+	m.Backups = leftBackups
+	return success
+}
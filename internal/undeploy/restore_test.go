@@ -0,0 +1,127 @@
+package undeploy
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/internal/manifest"
+	"github.com/backdround/deploy-configs/pkg/fstestutility"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+func TestRestoreBackups(t *testing.T) {
+	// makeManifest creates a backup file, a link instead of the original
+	// file and returns the manifest with them.
+	makeManifest := func(t *testing.T) *manifest.Manifest {
+		directory := t.TempDir()
+		originalPath := path.Join(directory, "original")
+		backupPath := path.Join(directory, "backups", "original")
+
+		fstestutility.AssertNoError(os.Mkdir(path.Dir(backupPath), 0755))
+		err := os.WriteFile(backupPath, []byte("data"), 0644)
+		fstestutility.AssertNoError(err)
+		fstestutility.AssertNoError(os.Symlink("/dev/null", originalPath))
+
+		m := manifest.New("pc1")
+		m.Links = []manifest.Link{{
+			Name:       "link1",
+			TargetPath: "/dev/null",
+			LinkPath:   originalPath,
+		}}
+		m.Backups = []manifest.Backup{{
+			Name:         "link1",
+			OriginalPath: originalPath,
+			BackupPath:   backupPath,
+		}}
+		return m
+	}
+
+	t.Run("BackupIsRestored", func(t *testing.T) {
+		m := makeManifest(t)
+		backup := m.Backups[0]
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success",
+			containsString(`Backup of "link1" link restored`)).Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RestoreBackups(m)
+
+		// Asserts that the original file is restored
+		require.True(t, success)
+		require.True(t, m.IsEmpty())
+		data, err := os.ReadFile(backup.OriginalPath)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+		require.NoFileExists(t, backup.BackupPath)
+	})
+
+	t.Run("OriginalPathIsOccupied", func(t *testing.T) {
+		m := makeManifest(t)
+		backup := m.Backups[0]
+		fstestutility.AssertNoError(os.Remove(backup.OriginalPath))
+		err := os.WriteFile(backup.OriginalPath, []byte("new data"), 0644)
+		fstestutility.AssertNoError(err)
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Warn", containsString(`"link1" link is left`)).Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RestoreBackups(m)
+
+		// Asserts that the backup is left
+		require.True(t, success)
+		require.Len(t, m.Backups, 1)
+		require.FileExists(t, backup.BackupPath)
+	})
+
+	t.Run("LinkWasChanged", func(t *testing.T) {
+		m := makeManifest(t)
+		backup := m.Backups[0]
+		fstestutility.AssertNoError(os.Remove(backup.OriginalPath))
+		err := os.Symlink("/dev/zero", backup.OriginalPath)
+		fstestutility.AssertNoError(err)
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Warn", containsString("was changed after deploying")).
+			Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RestoreBackups(m)
+
+		// Asserts that the user link and the backup are left
+		require.True(t, success)
+		require.Len(t, m.Backups, 1)
+		require.Len(t, m.Links, 1)
+		require.FileExists(t, backup.BackupPath)
+		require.True(t, fsutility.IsLinkPointsToDestination(
+			backup.OriginalPath, "/dev/zero"))
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		m := makeManifest(t)
+		backup := m.Backups[0]
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("would be restored")).Once()
+
+		// Executes the test
+		NewUndeployer(logger, Options{DryRun: true}).RestoreBackups(m)
+
+		// Asserts that nothing is changed
+		linkType := fsutility.GetPathType(backup.OriginalPath)
+		require.Equal(t, fsutility.Symlink.String(), linkType.String())
+		require.FileExists(t, backup.BackupPath)
+	})
+}
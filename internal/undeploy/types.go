@@ -0,0 +1,16 @@
+package undeploy
+
+// Options adjusts the behaviour of undeployer.
+type Options struct {
+	// DryRun makes undeployer only log planned removals without
+	// touching the filesystem.
+	DryRun bool
+}
+
+type Logger interface {
+	Success(message string)
+	Warn(message string)
+	Fail(message string)
+	// Skip logs units that are left untouched.
+	Skip(message string)
+}
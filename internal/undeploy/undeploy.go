@@ -0,0 +1,205 @@
+// undeploy describes undeployer which removes units recorded in
+// a manifest, but only if they still match what deploy-configs put
+// there. It logs all outcomes.
+package undeploy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/backdround/deploy-configs/internal/manifest"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/go-indent"
+)
+
+type undeployer struct {
+	logger  Logger
+	options Options
+}
+
+func NewUndeployer(logger Logger, options Options) undeployer {
+	return undeployer{
+		logger:  logger,
+		options: options,
+	}
+}
+
+func shift(message string, count int) string {
+	return indent.Indent(message, "  ", count)
+}
+
+// removalResult represents what happened to a unit during removing
+type removalResult int
+
+const (
+	removed removalResult = iota
+	alreadyRemoved
+	changed
+	failed
+)
+
+func (u undeployer) logResult(unitDescription string, description string,
+	result removalResult, err error) {
+	description = shift(description, 1)
+
+	switch result {
+	case removed:
+		if u.options.DryRun {
+			message := fmt.Sprintf("%v would be removed:\n%v",
+				unitDescription, description)
+			u.logger.Success(message)
+			return
+		}
+		message := fmt.Sprintf("%v removed:\n%v", unitDescription, description)
+		u.logger.Success(message)
+	case alreadyRemoved:
+		message := fmt.Sprintf("%v is already removed", unitDescription)
+		u.logger.Skip(message)
+	case changed:
+		message := fmt.Sprintf("%v is left, because it was changed "+
+			"after deploying:\n%v", unitDescription, description)
+		u.logger.Warn(message)
+	case failed:
+		errorMessage := shift("error: "+err.Error(), 2)
+		message := fmt.Sprintf("Unable to remove %v:\n%v\n%v",
+			unitDescription, description, errorMessage)
+		u.logger.Fail(message)
+	}
+}
+
+func (u undeployer) remove(path string) (removalResult, error) {
+	if u.options.DryRun {
+		return removed, nil
+	}
+
+	err := os.Remove(path)
+	if err != nil {
+		return failed, err
+	}
+	return removed, nil
+}
+
+// RemoveLink removes the link if it still points to the deployed target.
+func (u undeployer) RemoveLink(link manifest.Link) (success bool) {
+	result, err := func() (removalResult, error) {
+		switch fsutility.GetPathType(link.LinkPath) {
+		case fsutility.Notexisting:
+			return alreadyRemoved, nil
+		case fsutility.Symlink:
+			if fsutility.IsLinkPointsToDestination(link.LinkPath,
+				link.TargetPath) {
+				return u.remove(link.LinkPath)
+			}
+		}
+		return changed, nil
+	}()
+
+	description := fmt.Sprintf("target: %q\nlink: %q", link.TargetPath,
+		link.LinkPath)
+	unitDescription := fmt.Sprintf("Link %q", link.Name)
+	u.logResult(unitDescription, description, result, err)
+
+	return result != failed
+}
+
+// removeFile removes the file if its data wasn't changed after deploying.
+func (u undeployer) removeFile(unitDescription string,
+	file manifest.File) (success bool) {
+	result, err := func() (removalResult, error) {
+		switch fsutility.GetPathType(file.OutputPath) {
+		case fsutility.Notexisting:
+			return alreadyRemoved, nil
+		case fsutility.Regular:
+			fileHash := fsutility.GetFileHash(file.OutputPath)
+			if bytes.Equal(fileHash, file.Hash) {
+				return u.remove(file.OutputPath)
+			}
+		}
+		return changed, nil
+	}()
+
+	description := fmt.Sprintf("output: %q", file.OutputPath)
+	u.logResult(unitDescription, description, result, err)
+
+	return result != failed
+}
+
+// RemoveLinks removes all links of the manifest that still point to
+// their deployed targets. Links that aren't owned anymore are excluded
+// from the manifest.
+func (u undeployer) RemoveLinks(m *manifest.Manifest) (success bool) {
+	success = true
+	leftLinks := []manifest.Link{}
+	for _, link := range m.Links {
+		if !u.RemoveLink(link) {
+			success = false
+			leftLinks = append(leftLinks, link)
+		}
+	}
+
+	m.Links = leftLinks
+	return success
+}
+
+// RemoveTemplates removes all template outputs of the manifest that
+// weren't changed after deploying. Outputs that aren't owned anymore
+// are excluded from the manifest.
+func (u undeployer) RemoveTemplates(m *manifest.Manifest) (success bool) {
+	success = true
+	leftTemplates := []manifest.File{}
+	for _, template := range m.Templates {
+		unitDescription := fmt.Sprintf("Template %q output", template.Name)
+		if !u.removeFile(unitDescription, template) {
+			success = false
+			leftTemplates = append(leftTemplates, template)
+		}
+	}
+
+	m.Templates = leftTemplates
+	return success
+}
+
+// RemoveCommands removes all command outputs of the manifest that
+// weren't changed after deploying. Outputs that aren't owned anymore
+// are excluded from the manifest.
+func (u undeployer) RemoveCommands(m *manifest.Manifest) (success bool) {
+	success = true
+	leftCommands := []manifest.File{}
+	for _, command := range m.Commands {
+		unitDescription := fmt.Sprintf("Command %q output", command.Name)
+		if !u.removeFile(unitDescription, command) {
+			success = false
+			leftCommands = append(leftCommands, command)
+		}
+	}
+
+	m.Commands = leftCommands
+	return success
+}
+
+// RemoveDirectories removes all directories of the manifest that
+// became empty. Removed and missing directories are excluded from
+// the manifest.
+func (u undeployer) RemoveDirectories(m *manifest.Manifest) {
+	if u.options.DryRun {
+		for _, directory := range m.Directories {
+			message := fmt.Sprintf("Directory %q would be removed "+
+				"if it becomes empty", directory)
+			u.logger.Success(message)
+		}
+		return
+	}
+
+	for _, directory := range fsutility.RemoveEmptyDirectories(m.Directories) {
+		u.logger.Success(fmt.Sprintf("Directory %q removed", directory))
+	}
+
+	leftDirectories := []string{}
+	for _, directory := range m.Directories {
+		if fsutility.GetPathType(directory) == fsutility.Directory {
+			leftDirectories = append(leftDirectories, directory)
+		}
+	}
+	m.Directories = leftDirectories
+}
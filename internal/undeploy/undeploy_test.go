@@ -0,0 +1,210 @@
+package undeploy
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/internal/manifest"
+	"github.com/backdround/deploy-configs/pkg/fstestutility"
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+func TestRemoveLinks(t *testing.T) {
+	t.Run("LinkPointsToTarget", func(t *testing.T) {
+		// Creates a link
+		linkPath := fstestutility.GetAvailableTempPath()
+		fstestutility.AssertNoError(os.Symlink("/dev/null", linkPath))
+		defer os.Remove(linkPath)
+
+		m := manifest.New("pc1")
+		m.Links = []manifest.Link{{
+			Name:       "link1",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+		}}
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString(`Link "link1" removed`)).Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RemoveLinks(m)
+
+		// Asserts that the link was removed
+		require.True(t, success)
+		require.Empty(t, m.Links)
+		linkType := fsutility.GetPathType(linkPath)
+		require.Equal(t, fsutility.Notexisting.String(), linkType.String())
+	})
+
+	t.Run("LinkPointsElsewhere", func(t *testing.T) {
+		// Creates a link
+		linkPath := fstestutility.GetAvailableTempPath()
+		fstestutility.AssertNoError(os.Symlink("/dev/zero", linkPath))
+		defer os.Remove(linkPath)
+
+		m := manifest.New("pc1")
+		m.Links = []manifest.Link{{
+			Name:       "link1",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+		}}
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Warn", containsString(`Link "link1" is left`)).Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RemoveLinks(m)
+
+		// Asserts that the link wasn't changed
+		require.True(t, success)
+		require.Empty(t, m.Links)
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			"/dev/zero"))
+	})
+
+	t.Run("LinkIsAlreadyRemoved", func(t *testing.T) {
+		m := manifest.New("pc1")
+		m.Links = []manifest.Link{{
+			Name:       "link1",
+			TargetPath: "/dev/null",
+			LinkPath:   fstestutility.GetAvailableTempPath(),
+		}}
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Skip", containsString("already removed")).Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RemoveLinks(m)
+
+		// Asserts
+		require.True(t, success)
+		require.Empty(t, m.Links)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		// Creates a link
+		linkPath := fstestutility.GetAvailableTempPath()
+		fstestutility.AssertNoError(os.Symlink("/dev/null", linkPath))
+		defer os.Remove(linkPath)
+
+		m := manifest.New("pc1")
+		m.Links = []manifest.Link{{
+			Name:       "link1",
+			TargetPath: "/dev/null",
+			LinkPath:   linkPath,
+		}}
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Success", containsString("would be removed")).Once()
+
+		// Executes the test
+		options := Options{DryRun: true}
+		NewUndeployer(logger, options).RemoveLinks(m)
+
+		// Asserts that the link wasn't removed
+		require.True(t, fsutility.IsLinkPointsToDestination(linkPath,
+			"/dev/null"))
+	})
+}
+
+func TestRemoveTemplates(t *testing.T) {
+	t.Run("OutputIsUnchanged", func(t *testing.T) {
+		outputPath, cleanup := fstestutility.CreateTemporaryFileWithData("data")
+		defer cleanup()
+
+		m := manifest.New("pc1")
+		m.Templates = []manifest.File{{
+			Name:       "template1",
+			OutputPath: outputPath,
+			Hash:       fsutility.GetHash([]byte("data")),
+		}}
+
+		// Executes the test
+		success := NewUndeployer(getLoggerDummy(), Options{}).RemoveTemplates(m)
+
+		// Asserts that the output was removed
+		require.True(t, success)
+		require.Empty(t, m.Templates)
+		outputType := fsutility.GetPathType(outputPath)
+		require.Equal(t, fsutility.Notexisting.String(), outputType.String())
+	})
+
+	t.Run("OutputIsModified", func(t *testing.T) {
+		outputPath, cleanup := fstestutility.CreateTemporaryFileWithData("edit")
+		defer cleanup()
+
+		m := manifest.New("pc1")
+		m.Templates = []manifest.File{{
+			Name:       "template1",
+			OutputPath: outputPath,
+			Hash:       fsutility.GetHash([]byte("data")),
+		}}
+
+		// Sets up the mock
+		logger := &LoggerMock{}
+		defer logger.AssertExpectations(t)
+		logger.On("Warn", containsString(`Template "template1" output`)).Once()
+
+		// Executes the test
+		success := NewUndeployer(logger, Options{}).RemoveTemplates(m)
+
+		// Asserts that the output wasn't removed
+		require.True(t, success)
+		outputType := fsutility.GetPathType(outputPath)
+		require.Equal(t, fsutility.Regular.String(), outputType.String())
+	})
+}
+
+func TestRemoveCommands(t *testing.T) {
+	outputPath, cleanup := fstestutility.CreateTemporaryFileWithData("data")
+	defer cleanup()
+
+	m := manifest.New("pc1")
+	m.Commands = []manifest.File{{
+		Name:       "command1",
+		OutputPath: outputPath,
+		Hash:       fsutility.GetHash([]byte("data")),
+	}}
+
+	// Executes the test
+	success := NewUndeployer(getLoggerDummy(), Options{}).RemoveCommands(m)
+
+	// Asserts that the output was removed
+	require.True(t, success)
+	require.Empty(t, m.Commands)
+	outputType := fsutility.GetPathType(outputPath)
+	require.Equal(t, fsutility.Notexisting.String(), outputType.String())
+}
+
+func TestRemoveDirectories(t *testing.T) {
+	// Creates directories
+	rootDirectory := fstestutility.GetAvailableTempPath()
+	emptyDirectory := fstestutility.MakeDirectory(rootDirectory, "empty")
+	notEmptyDirectory := fstestutility.MakeDirectory(rootDirectory, "data")
+	err := os.WriteFile(path.Join(notEmptyDirectory, "file"), nil, 0644)
+	fstestutility.AssertNoError(err)
+	defer os.RemoveAll(rootDirectory)
+
+	m := manifest.New("pc1")
+	m.Directories = []string{emptyDirectory, notEmptyDirectory}
+
+	// Executes the test
+	NewUndeployer(getLoggerDummy(), Options{}).RemoveDirectories(m)
+
+	// Asserts that only the empty directory was removed
+	require.Equal(t, []string{notEmptyDirectory}, m.Directories)
+	emptyDirectoryType := fsutility.GetPathType(emptyDirectory)
+	require.Equal(t, fsutility.Notexisting.String(),
+		emptyDirectoryType.String())
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 )
 
 // FindEntryDescending searches an directory entry from the given
@@ -72,18 +73,123 @@ func GetHash(data []byte) []byte {
 }
 
 // MakeDirectoryIfDoesntExist creates directory if it doesn't exist.
-// the error is return if unable to create directory.
-func MakeDirectoryIfDoesntExist(directory string) error {
+// It returns all directories that were created, from the outermost
+// to the innermost. The error is return if unable to create directory.
+func MakeDirectoryIfDoesntExist(directory string) (
+	createdDirectories []string, err error) {
 	stat, err := os.Stat(directory)
 	if err == nil {
 		if stat.IsDir() {
+			return nil, nil
+		}
+		pattern := "unable to create directory, because file exists: %q"
+		return nil, fmt.Errorf(pattern, directory)
+	}
+
+	// Gets directories that don't exist
+	missingDirectories := []string{}
+	currentPath := path.Clean(directory)
+	for GetPathType(currentPath) == Notexisting {
+		missingDirectories = append([]string{currentPath}, missingDirectories...)
+		if currentPath == path.Dir(currentPath) {
+			break
+		}
+		currentPath = path.Dir(currentPath)
+	}
+
+	err = os.MkdirAll(directory, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return missingDirectories, nil
+}
+
+// CheckDirectoryCanBeMade checks that MakeDirectoryIfDoesntExist is able
+// to create the directory without actually creating it.
+func CheckDirectoryCanBeMade(directory string) error {
+	for currentPath := directory; ; currentPath = path.Dir(currentPath) {
+		switch GetPathType(currentPath) {
+		case Directory:
+			return nil
+		case Notexisting:
+			if currentPath == path.Dir(currentPath) {
+				return nil
+			}
+			continue
+		}
+
+		stat, err := os.Stat(currentPath)
+		if err == nil && stat.IsDir() {
 			return nil
 		}
 		pattern := "unable to create directory, because file exists: %q"
-		return fmt.Errorf(pattern, directory)
+		return fmt.Errorf(pattern, currentPath)
+	}
+}
+
+// RemoveEmptyDirectories removes the given directories if they are empty.
+// Directories are removed from the innermost to the outermost, so nested
+// directories are removed first. It returns removed directories.
+func RemoveEmptyDirectories(directories []string) (removed []string) {
+	sortedDirectories := append([]string{}, directories...)
+	sort.Slice(sortedDirectories, func(i int, j int) bool {
+		return len(sortedDirectories[i]) > len(sortedDirectories[j])
+	})
+
+	for _, directory := range sortedDirectories {
+		if GetPathType(directory) != Directory {
+			continue
+		}
+
+		entries, err := os.ReadDir(directory)
+		if err != nil || len(entries) != 0 {
+			continue
+		}
+
+		if os.Remove(directory) == nil {
+			removed = append(removed, directory)
+		}
+	}
+
+	return removed
+}
+
+// WriteFileAtomically writes data to a temporary file in the same
+// directory and renames it to filePath, so filePath is never left
+// truncated. An existing regular file keeps its permissions, otherwise
+// perm is used. A symlink on filePath is replaced, not followed.
+func WriteFileAtomically(filePath string, data []byte,
+	perm os.FileMode) error {
+	if info, err := os.Lstat(filePath); err == nil && info.Mode().IsRegular() {
+		perm = info.Mode().Perm()
+	}
+
+	file, err := os.CreateTemp(path.Dir(filePath), "."+path.Base(filePath)+
+		".*.tmp")
+	if err != nil {
+		return err
+	}
+	temporaryPath := file.Name()
+	defer os.Remove(temporaryPath)
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(temporaryPath, perm)
+	if err != nil {
+		return err
 	}
 
-	return os.MkdirAll(directory, 0755)
+	return os.Rename(temporaryPath, filePath)
 }
 
 func IsLinkPointsToDestination(linkPath string, destination string) bool {
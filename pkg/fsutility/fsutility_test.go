@@ -111,13 +111,16 @@ func TestMakeDirectoryIfDoesntExist(t *testing.T) {
 		defer os.RemoveAll(rootDirectory)
 
 		// Executes the test
-		err := MakeDirectoryIfDoesntExist(newDirectory)
+		createdDirectories, err := MakeDirectoryIfDoesntExist(newDirectory)
 		require.NoError(t, err)
 
 		// Asserts that the directory was created
 		stat, err := os.Stat(newDirectory)
 		require.NoError(t, err)
 		require.True(t, stat.IsDir())
+
+		expectedDirectories := []string{rootDirectory, newDirectory}
+		require.Equal(t, expectedDirectories, createdDirectories)
 	})
 
 	t.Run("FailOnExistingFile", func(t *testing.T) {
@@ -129,7 +132,7 @@ func TestMakeDirectoryIfDoesntExist(t *testing.T) {
 		dirictoryToCreate := file.Name()
 
 		// Executes the test
-		err = MakeDirectoryIfDoesntExist(dirictoryToCreate)
+		_, err = MakeDirectoryIfDoesntExist(dirictoryToCreate)
 
 		// Asserts
 		require.Error(t, err)
@@ -142,10 +145,122 @@ func TestMakeDirectoryIfDoesntExist(t *testing.T) {
 		defer os.Remove(directory)
 
 		// Executes the test
-		err = MakeDirectoryIfDoesntExist(directory)
+		createdDirectories, err := MakeDirectoryIfDoesntExist(directory)
 
 		// Asserts
 		require.NoError(t, err)
+		require.Empty(t, createdDirectories)
+	})
+}
+
+func TestCheckDirectoryCanBeMade(t *testing.T) {
+	t.Run("NestedDirectoryDoesntExist", func(t *testing.T) {
+		rootDirectory := fstestutility.GetAvailableTempPath()
+		newDirectory := path.Join(rootDirectory, "level-two")
+
+		// Executes the test
+		err := CheckDirectoryCanBeMade(newDirectory)
+		require.NoError(t, err)
+
+		// Asserts that the directory wasn't created
+		require.Equal(t, Notexisting.String(), GetPathType(rootDirectory).String())
+	})
+
+	t.Run("FileInPath", func(t *testing.T) {
+		file, cleanup := fstestutility.CreateTemporaryFileWithData("data")
+		defer cleanup()
+
+		// Executes the test
+		err := CheckDirectoryCanBeMade(path.Join(file, "level-two"))
+		require.Error(t, err)
+	})
+
+	t.Run("DirectoryExists", func(t *testing.T) {
+		err := CheckDirectoryCanBeMade(os.TempDir())
+		require.NoError(t, err)
+	})
+}
+
+func TestRemoveEmptyDirectories(t *testing.T) {
+	// Creates a test directory tree
+	rootDirectory := fstestutility.GetAvailableTempPath()
+	emptyDirectory := path.Join(rootDirectory, "a", "b")
+	fstestutility.MakeDirectory(emptyDirectory)
+	notEmptyDirectory := fstestutility.MakeDirectory(rootDirectory, "c")
+	err := os.WriteFile(path.Join(notEmptyDirectory, "file"), nil, 0644)
+	fstestutility.AssertNoError(err)
+	defer os.RemoveAll(rootDirectory)
+
+	// Executes the test
+	directories := []string{
+		rootDirectory,
+		path.Join(rootDirectory, "a"),
+		emptyDirectory,
+		notEmptyDirectory,
+	}
+	removed := RemoveEmptyDirectories(directories)
+
+	// Asserts that only empty directories were removed
+	expectedRemoved := []string{emptyDirectory, path.Join(rootDirectory, "a")}
+	require.Equal(t, expectedRemoved, removed)
+	require.Equal(t, Directory.String(), GetPathType(notEmptyDirectory).String())
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	t.Run("NewFile", func(t *testing.T) {
+		filePath := path.Join(t.TempDir(), "file")
+
+		err := WriteFileAtomically(filePath, []byte("data"), 0640)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+
+		info, err := os.Stat(filePath)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	})
+
+	t.Run("ExistingFileKeepsPermissions", func(t *testing.T) {
+		directory := t.TempDir()
+		filePath := path.Join(directory, "file")
+		err := os.WriteFile(filePath, []byte("old data"), 0600)
+		require.NoError(t, err)
+
+		err = WriteFileAtomically(filePath, []byte("data"), 0644)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+
+		info, err := os.Stat(filePath)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		// Asserts that temporary files are removed
+		entries, err := os.ReadDir(directory)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("SymlinkIsReplaced", func(t *testing.T) {
+		directory := t.TempDir()
+		targetPath := path.Join(directory, "target")
+		err := os.WriteFile(targetPath, []byte("target data"), 0644)
+		require.NoError(t, err)
+		filePath := path.Join(directory, "file")
+		err = os.Symlink(targetPath, filePath)
+		require.NoError(t, err)
+
+		err = WriteFileAtomically(filePath, []byte("data"), 0644)
+		require.NoError(t, err)
+
+		require.Equal(t, Regular.String(), GetPathType(filePath).String())
+		data, err := os.ReadFile(targetPath)
+		require.NoError(t, err)
+		require.Equal(t, "target data", string(data))
 	})
 }
 
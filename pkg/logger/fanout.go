@@ -0,0 +1,58 @@
+package logger
+
+// fanoutLogger passes every message to all its loggers.
+type fanoutLogger []Logger
+
+// Fanout returns a logger that passes every message to all the given
+// loggers.
+func Fanout(loggers ...Logger) Logger {
+	return fanoutLogger(loggers)
+}
+
+func (l fanoutLogger) Title(title string) {
+	for _, logger := range l {
+		logger.Title(title)
+	}
+}
+
+func (l fanoutLogger) Success(message string) {
+	for _, logger := range l {
+		logger.Success(message)
+	}
+}
+
+func (l fanoutLogger) Warn(message string) {
+	for _, logger := range l {
+		logger.Warn(message)
+	}
+}
+
+func (l fanoutLogger) Fail(message string) {
+	for _, logger := range l {
+		logger.Fail(message)
+	}
+}
+
+func (l fanoutLogger) Log(message string) {
+	for _, logger := range l {
+		logger.Log(message)
+	}
+}
+
+func (l fanoutLogger) Skip(message string) {
+	for _, logger := range l {
+		logger.Skip(message)
+	}
+}
+
+func (l fanoutLogger) Verbose(message string) {
+	for _, logger := range l {
+		logger.Verbose(message)
+	}
+}
+
+func (l fanoutLogger) Debug(message string) {
+	for _, logger := range l {
+		logger.Debug(message)
+	}
+}
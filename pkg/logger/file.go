@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+)
+
+// fileLogger logs all messages as plain tagged lines with timestamps.
+type fileLogger struct {
+	output io.Writer
+	now    func() time.Time
+}
+
+// NewFileLogger returns a logger that writes every message to the
+// output without colors. It's used to keep logs of runs.
+func NewFileLogger(output io.Writer) Logger {
+	return &fileLogger{
+		output: output,
+		now:    time.Now,
+	}
+}
+
+func (l *fileLogger) write(tag string, message string) {
+	timestamp := l.now().Format("2006-01-02 15:04:05")
+	writeTagged(l.output, timestamp+" "+tag, message)
+}
+
+func (l *fileLogger) Title(title string) {
+	l.write("[title]", title)
+}
+
+func (l *fileLogger) Success(message string) {
+	l.write("[ok]", message)
+}
+
+func (l *fileLogger) Warn(message string) {
+	l.write("[warn]", message)
+}
+
+func (l *fileLogger) Fail(message string) {
+	l.write("[fail]", message)
+}
+
+func (l *fileLogger) Log(message string) {
+	l.write("[log]", message)
+}
+
+func (l *fileLogger) Skip(message string) {
+	l.write("[skip]", message)
+}
+
+func (l *fileLogger) Verbose(message string) {
+	l.write("[verbose]", message)
+}
+
+func (l *fileLogger) Debug(message string) {
+	l.write("[debug]", message)
+}
+
+// OpenFile opens the file to append logs. If the file has reached
+// maxSize, it's rotated: it's renamed to <filePath>.1 and older files
+// are shifted up to <filePath>.<backupCount>.
+func OpenFile(filePath string, maxSize int64, backupCount int) (
+	*os.File, error) {
+	_, err := fsutility.MakeDirectoryIfDoesntExist(path.Dir(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err == nil && info.Size() >= maxSize {
+		err = rotate(filePath, backupCount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+}
+
+// rotate shifts old files and renames the file to <filePath>.1. The
+// oldest file is overwritten.
+func rotate(filePath string, backupCount int) error {
+	if backupCount < 1 {
+		return os.Remove(filePath)
+	}
+
+	for i := backupCount - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%v.%v", filePath, i),
+			fmt.Sprintf("%v.%v", filePath, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(filePath, filePath+".1")
+}
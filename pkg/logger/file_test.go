@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLogger(t *testing.T) {
+	output := &bytes.Buffer{}
+	l := &fileLogger{
+		output: output,
+		now: func() time.Time {
+			return time.Date(2023, 4, 5, 6, 7, 8, 0, time.Local)
+		},
+	}
+
+	l.Skip("Link \"link1\" is skipped")
+	l.Debug("Command \"command1\" output:\n  error")
+
+	expected := "2023-04-05 06:07:08 [skip] Link \"link1\" is skipped\n" +
+		"2023-04-05 06:07:08 [debug] Command \"command1\" output:\n" +
+		"2023-04-05 06:07:08 [debug]   error\n"
+	require.Equal(t, expected, output.String())
+}
+
+func TestOpenFile(t *testing.T) {
+	t.Run("Append", func(t *testing.T) {
+		filePath := path.Join(t.TempDir(), "logs", "run.log")
+
+		for _, message := range []string{"first\n", "second\n"} {
+			file, err := OpenFile(filePath, 1024, 2)
+			require.NoError(t, err)
+			_, err = file.WriteString(message)
+			require.NoError(t, err)
+			require.NoError(t, file.Close())
+		}
+
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.Equal(t, "first\nsecond\n", string(data))
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		filePath := path.Join(t.TempDir(), "run.log")
+
+		for _, message := range []string{"1", "2", "3", "4"} {
+			file, err := OpenFile(filePath, 1, 2)
+			require.NoError(t, err)
+			_, err = file.WriteString(message)
+			require.NoError(t, err)
+			require.NoError(t, file.Close())
+		}
+
+		for suffix, expected := range map[string]string{
+			"": "4", ".1": "3", ".2": "2",
+		} {
+			data, err := os.ReadFile(filePath + suffix)
+			require.NoError(t, err)
+			require.Equal(t, expected, string(data))
+		}
+		require.NoFileExists(t, filePath+".3")
+	})
+}
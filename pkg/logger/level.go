@@ -0,0 +1,75 @@
+package logger
+
+// Level describes which messages are logged.
+type Level int
+
+const (
+	// LevelQuiet logs only failures. Log messages are passed at every
+	// level, because they are output of commands like lists and diffs.
+	LevelQuiet Level = iota
+	// LevelNormal logs changes and failures.
+	LevelNormal
+	// LevelVerbose logs also verbose messages.
+	LevelVerbose
+	// LevelDebug logs everything.
+	LevelDebug
+)
+
+// levelLogger passes only messages that are allowed by the level.
+type levelLogger struct {
+	logger Logger
+	level  Level
+}
+
+// WithLevel returns a logger that passes to l only messages that are
+// allowed by the level.
+func WithLevel(l Logger, level Level) Logger {
+	return levelLogger{
+		logger: l,
+		level:  level,
+	}
+}
+
+func (l levelLogger) Title(title string) {
+	if l.level >= LevelNormal {
+		l.logger.Title(title)
+	}
+}
+
+func (l levelLogger) Success(message string) {
+	if l.level >= LevelNormal {
+		l.logger.Success(message)
+	}
+}
+
+func (l levelLogger) Warn(message string) {
+	if l.level >= LevelNormal {
+		l.logger.Warn(message)
+	}
+}
+
+func (l levelLogger) Fail(message string) {
+	l.logger.Fail(message)
+}
+
+func (l levelLogger) Log(message string) {
+	l.logger.Log(message)
+}
+
+func (l levelLogger) Skip(message string) {
+	if l.level >= LevelVerbose {
+		l.logger.Skip(message)
+	}
+}
+
+func (l levelLogger) Verbose(message string) {
+	if l.level >= LevelVerbose {
+		l.logger.Verbose(message)
+	}
+}
+
+func (l levelLogger) Debug(message string) {
+	if l.level >= LevelDebug {
+		l.logger.Debug(message)
+	}
+}
@@ -3,7 +3,12 @@ package logger
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
 type Logger interface {
@@ -12,34 +17,133 @@ type Logger interface {
 	Warn(message string)
 	Fail(message string)
 	Log(message string)
+	// Skip logs units that are left untouched.
+	Skip(message string)
+	// Verbose logs details like expanded paths and executed command lines.
+	Verbose(message string)
+	// Debug logs the most detailed messages like outputs of commands.
+	Debug(message string)
 }
 
-type logger struct{}
+// ColorMode describes when messages are colored.
+type ColorMode string
+
+const (
+	// ColorAuto colors messages only if stdout is a terminal and NO_COLOR
+	// isn't set.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways colors messages everywhere.
+	ColorAlways ColorMode = "always"
+	// ColorNever logs messages without colors.
+	ColorNever ColorMode = "never"
+)
+
+// ColorModeSetter is implemented by loggers which are able to change
+// their color mode.
+type ColorModeSetter interface {
+	SetColorMode(mode ColorMode)
+}
+
+type logger struct {
+	output    io.Writer
+	terminal  bool
+	colorMode ColorMode
+}
 
 func New() Logger {
-	return &logger{}
+	return &logger{
+		output: color.Output,
+		terminal: isatty.IsTerminal(os.Stdout.Fd()) ||
+			isatty.IsCygwinTerminal(os.Stdout.Fd()),
+		colorMode: ColorAuto,
+	}
+}
+
+func (l *logger) SetColorMode(mode ColorMode) {
+	l.colorMode = mode
+}
+
+// isPlain checks that messages have to be logged as plain tagged lines,
+// because nobody looks at them in a terminal. The color mode affects
+// only escape codes.
+func (l *logger) isPlain() bool {
+	return !l.terminal
+}
+
+// isColored checks that messages have to be colored.
+func (l *logger) isColored() bool {
+	switch l.colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return l.terminal && os.Getenv("NO_COLOR") == ""
+}
+
+// print logs the message with the given attributes in a terminal and
+// prefixes every line of the message with the tag otherwise.
+func (l *logger) print(tag string, message string,
+	attributes ...color.Attribute) {
+	c := color.New(attributes...)
+	if l.isColored() {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+
+	if l.isPlain() {
+		taggedMessage := &strings.Builder{}
+		writeTagged(taggedMessage, tag, message)
+		c.Fprint(l.output, taggedMessage.String())
+		return
+	}
+
+	c.Fprintln(l.output, message)
+}
+
+// writeTagged writes every line of the message with the tag.
+func writeTagged(output io.Writer, tag string, message string) {
+	for _, line := range strings.Split(message, "\n") {
+		fmt.Fprintln(output, strings.TrimRight(tag+" "+line, " "))
+	}
+}
+
+func (l *logger) Title(title string) {
+	if l.isPlain() {
+		l.print("[title]", title)
+		return
+	}
+	l.print("", fmt.Sprintf("\n--- %v ---", title), color.FgHiBlue,
+		color.Bold)
+}
+
+func (l *logger) Success(message string) {
+	l.print("[ok]", message, color.FgHiGreen)
+}
+
+func (l *logger) Warn(message string) {
+	l.print("[warn]", message, color.FgYellow)
 }
 
-func (l logger) Title(title string) {
-	c := color.New(color.FgHiBlue, color.Bold)
-	c.Printf("\n--- %v ---\n", title)
+func (l *logger) Fail(message string) {
+	l.print("[fail]", message, color.FgHiRed, color.Bold)
 }
 
-func (l logger) Success(message string) {
-	c := color.New(color.FgHiGreen)
-	c.Println(message)
+// Log logs the message as is, because it can be a data like a json or
+// a diff.
+func (l *logger) Log(message string) {
+	fmt.Fprintln(l.output, message)
 }
 
-func (l logger) Warn(message string) {
-	c := color.New(color.FgYellow)
-	c.Println(message)
+func (l *logger) Skip(message string) {
+	l.print("[skip]", message)
 }
 
-func (l logger) Fail(message string) {
-	c := color.New(color.FgHiRed, color.Bold)
-	c.Println(message)
+func (l *logger) Verbose(message string) {
+	l.print("[verbose]", message)
 }
 
-func (l logger) Log(message string) {
-	fmt.Println(message)
+func (l *logger) Debug(message string) {
+	l.print("[debug]", message, color.Faint)
 }
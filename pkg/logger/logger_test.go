@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainOutput(t *testing.T) {
+	output := &bytes.Buffer{}
+	l := &logger{output: output, terminal: false, colorMode: ColorAuto}
+
+	l.Title("Create links")
+	l.Success("Link \"link1\" created:\n  target: \"/a\"")
+	l.Skip("Link \"link2\" is skipped")
+	l.Fail("Unable to create \"link3\" link")
+	l.Log("{\"type\":\"message\"}")
+
+	expected := "[title] Create links\n" +
+		"[ok] Link \"link1\" created:\n" +
+		"[ok]   target: \"/a\"\n" +
+		"[skip] Link \"link2\" is skipped\n" +
+		"[fail] Unable to create \"link3\" link\n" +
+		"{\"type\":\"message\"}\n"
+	require.Equal(t, expected, output.String())
+}
+
+func TestTerminalOutput(t *testing.T) {
+	t.Run("NoColor", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		output := &bytes.Buffer{}
+		l := &logger{output: output, terminal: true, colorMode: ColorAuto}
+
+		l.Title("Create links")
+		l.Success("Link \"link1\" created")
+
+		expected := "\n--- Create links ---\nLink \"link1\" created\n"
+		require.Equal(t, expected, output.String())
+	})
+
+	t.Run("ColorNever", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		l := &logger{output: output, terminal: true, colorMode: ColorNever}
+
+		l.Fail("Unable to create \"link1\" link")
+
+		require.Equal(t, "Unable to create \"link1\" link\n", output.String())
+	})
+
+	t.Run("ColorAlways", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		l := &logger{output: output, terminal: true, colorMode: ColorAlways}
+
+		l.Success("Link \"link1\" created")
+
+		require.Contains(t, output.String(), "\x1b[")
+		require.Contains(t, output.String(), "Link \"link1\" created")
+	})
+}
+
+func TestColorModeOfPlainOutput(t *testing.T) {
+	t.Run("ColorNever", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		l := &logger{output: output, terminal: false, colorMode: ColorNever}
+
+		l.Title("Create links")
+		l.Fail("Unable to create \"link1\" link")
+
+		expected := "[title] Create links\n" +
+			"[fail] Unable to create \"link1\" link\n"
+		require.Equal(t, expected, output.String())
+	})
+
+	t.Run("ColorAlways", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		l := &logger{output: output, terminal: false, colorMode: ColorAlways}
+
+		l.Success("Link \"link1\" created")
+
+		require.Contains(t, output.String(), "\x1b[")
+		require.Contains(t, output.String(), "[ok] Link \"link1\" created")
+		require.NotContains(t, output.String(), "---")
+	})
+}
@@ -0,0 +1,62 @@
+// xdg describes base directories in accordance with the XDG Base
+// Directory Specification.
+package xdg
+
+import (
+	"os"
+	"os/user"
+	"path"
+)
+
+// getHomeDirectory searches the home directory
+func getHomeDirectory() (string, error) {
+	homedir, err := os.UserHomeDir()
+	if err == nil {
+		return homedir, nil
+	}
+
+	user, err := user.Current()
+	if err == nil {
+		return user.HomeDir, nil
+	}
+
+	return "", err
+}
+
+// getBaseDirectory returns an absolute path from the environment
+// variable or a default path relative to the home directory.
+func getBaseDirectory(variable string, defaultRelativePath string) (
+	string, error) {
+	// The specification requires paths to be absolute, otherwise
+	// they should be ignored.
+	directory := os.Getenv(variable)
+	if path.IsAbs(directory) {
+		return directory, nil
+	}
+
+	home, err := getHomeDirectory()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(home, defaultRelativePath), nil
+}
+
+// StateHome returns $XDG_STATE_HOME or its default ~/.local/state
+func StateHome() (string, error) {
+	return getBaseDirectory("XDG_STATE_HOME", ".local/state")
+}
+
+// ConfigHome returns $XDG_CONFIG_HOME or its default ~/.config
+func ConfigHome() (string, error) {
+	return getBaseDirectory("XDG_CONFIG_HOME", ".config")
+}
+
+// DataHome returns $XDG_DATA_HOME or its default ~/.local/share
+func DataHome() (string, error) {
+	return getBaseDirectory("XDG_DATA_HOME", ".local/share")
+}
+
+// CacheHome returns $XDG_CACHE_HOME or its default ~/.cache
+func CacheHome() (string, error) {
+	return getBaseDirectory("XDG_CACHE_HOME", ".cache")
+}
@@ -0,0 +1,55 @@
+package xdg
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateHome(t *testing.T) {
+	t.Run("VariableIsSet", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/some/state")
+
+		stateHome, err := StateHome()
+		require.NoError(t, err)
+		require.Equal(t, "/some/state", stateHome)
+	})
+
+	t.Run("VariableIsRelative", func(t *testing.T) {
+		t.Setenv("HOME", "/home/user")
+		t.Setenv("XDG_STATE_HOME", "some/state")
+
+		stateHome, err := StateHome()
+		require.NoError(t, err)
+		require.Equal(t, path.Join("/home/user", ".local/state"), stateHome)
+	})
+
+	t.Run("VariableIsntSet", func(t *testing.T) {
+		t.Setenv("HOME", "/home/user")
+		t.Setenv("XDG_STATE_HOME", "")
+
+		stateHome, err := StateHome()
+		require.NoError(t, err)
+		require.Equal(t, "/home/user/.local/state", stateHome)
+	})
+}
+
+func TestDefaultBaseDirectories(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	configHome, err := ConfigHome()
+	require.NoError(t, err)
+	require.Equal(t, "/home/user/.config", configHome)
+
+	dataHome, err := DataHome()
+	require.NoError(t, err)
+	require.Equal(t, "/home/user/.local/share", dataHome)
+
+	cacheHome, err := CacheHome()
+	require.NoError(t, err)
+	require.Equal(t, "/home/user/.cache", cacheHome)
+}
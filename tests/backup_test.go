@@ -0,0 +1,74 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestBackup(t *testing.T) {
+	getFileTree := func(backup string) string {
+		return `
+			.git:
+			configs:
+				link.conf:
+					type: file
+			deploy:
+				link1:
+					type: file
+					data: "original data"
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						pc1:
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/link.conf"
+									link: "{{.GitRoot}}/deploy/link1"
+									backup: ` + backup + `
+		`
+	}
+
+	t.Run("OccupiedLinkPathFailsWithoutBackup", func(t *testing.T) {
+		c := testcase.RunCase(t, getFileTree("false"), "./run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "link path is occupied")
+	})
+
+	t.Run("LinkBackup", func(t *testing.T) {
+		c := testcase.RunCase(t, getFileTree("true"), "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Link "link1" created:`)
+
+		m := c.ReadManifest(t, "pc1")
+		require.Len(t, m.Backups, 1)
+		require.Equal(t, c.Root()+"/deploy/link1", m.Backups[0].OriginalPath)
+		require.FileExists(t, m.Backups[0].BackupPath)
+	})
+
+	t.Run("GlobalBackupAndRestore", func(t *testing.T) {
+		fileTree := getFileTree("false")
+		c := testcase.RunCase(t, fileTree, "./run", "--backup", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		c.Rerun("./run", "restore", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Backup of "link1" link restored`)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				link.conf:
+					type: file
+			deploy:
+				link1:
+					type: file
+					data: "original data"
+			deploy-configs.yaml:
+				type: file
+		`)
+		require.NoFileExists(t, c.ManifestPath("pc1"))
+	})
+}
@@ -111,10 +111,10 @@ func TestCommands(t *testing.T) {
 									command: "rev {{.Input}} > {{.Output}}"
 		`
 
-		c := testcase.RunCase(t, fileTree, "./run", "pc1")
+		c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 		c.RequireReturnCode(t, 0)
 		c.RequireFileTree(t, fileTree)
-		c.RequireLogMessage(t, `Command "data-rev" is skipped`)
+		c.RequireVerboseMessage(t, `Command "data-rev" is skipped`)
 	})
 
 	t.Run("Fail", func(t *testing.T) {
@@ -179,6 +179,33 @@ func TestCommands(t *testing.T) {
 			c.RequireFailMessage(t, expectedMessage)
 		})
 
+		t.Run("ExecutionFailKeepsOutput", func(t *testing.T) {
+			fileTree := `
+				.git:
+				data.txt:
+					type: file
+					data: some data
+				data-rev.txt:
+					type: file
+					data: atad emos
+				deploy-configs.yaml:
+					type: file
+					data: |
+						instances:
+							pc1:
+								commands:
+									data-rev:
+										input: "{{.GitRoot}}/data.txt"
+										output: "{{.GitRoot}}/data-rev.txt"
+										command: "echo partial > {{.Output}} && false"
+			`
+
+			c := testcase.RunCase(t, fileTree, "./run", "pc1")
+			c.RequireReturnCode(t, 1)
+			c.RequireFileTree(t, fileTree)
+			c.RequireFailMessage(t, "error: exit status 1")
+		})
+
 		t.Run("OutputPathIsUnreachable", func(t *testing.T) {
 			fileTree := `
 				.git:
@@ -240,7 +267,7 @@ func TestCommands(t *testing.T) {
 					command: "rev {{.Input}} > {{.Output}}"
 						error: unable to replace output path
 			`
-			expectedSpecificMessage := "data-rev: directory not empty"
+			expectedSpecificMessage := "output path is a directory"
 
 			c := testcase.RunCase(t, fileTree, "./run", "pc1")
 			c.RequireReturnCode(t, 1)
@@ -0,0 +1,74 @@
+package tests_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestConfigPath(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+	`
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+		c := testcase.RunCase(t, initialFileTree, "./run", "status", "pc1")
+		c.Chdir(t, "..")
+		c.Rerun("./run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "unable to find config path")
+	})
+
+	t.Run("Flag", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "status", "pc1")
+		c.Chdir(t, "..")
+		c.Rerun("./run", "--config", c.Root()+"/deploy-configs.yaml", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Link "link1" created`)
+	})
+
+	t.Run("Environment", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "status", "pc1")
+		c.Chdir(t, "..")
+		t.Setenv("DEPLOY_CONFIGS_FILE", c.Root()+"/deploy-configs.yaml")
+		c.Rerun("./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Link "link1" created`)
+	})
+
+	t.Run("UserConfigDirectory", func(t *testing.T) {
+		configHome := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", configHome)
+
+		c := testcase.RunCase(t, initialFileTree, "./run", "status", "pc1")
+		c.Chdir(t, "..")
+
+		// Links the config to the user config directory
+		userConfigDirectory := path.Join(configHome, "deploy-configs")
+		require.NoError(t, os.Mkdir(userConfigDirectory, 0755))
+		require.NoError(t, os.Symlink(c.Root()+"/deploy-configs.yaml",
+			path.Join(userConfigDirectory, "config.yaml")))
+
+		c.Rerun("./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Link "link1" created`)
+	})
+}
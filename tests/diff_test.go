@@ -0,0 +1,58 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestDiff(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			template.conf:
+				type: file
+				data: "var = {{.var}}\n"
+			command.conf:
+				type: file
+				data: "some data\n"
+		deploy:
+			template1:
+				type: file
+				data: "var = 4\n"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									var: 3
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	c := testcase.RunCase(t, initialFileTree, "./run", "diff", "pc1")
+	c.RequireReturnCode(t, 0)
+	c.RequireFileTree(t, initialFileTree)
+
+	c.RequireWarnMessage(t, `Template "template1" differs:`)
+	c.RequireLogMessage(t, "--- {Root}/deploy/template1")
+	c.RequireLogMessage(t, `
+		@@ -1 +1 @@
+		-var = 4
+		+var = 3
+	`)
+
+	c.RequireWarnMessage(t, `Command "command1" output differs:`)
+	c.RequireLogMessage(t, `
+		@@ -0,0 +1 @@
+		+some data
+	`)
+}
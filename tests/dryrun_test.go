@@ -0,0 +1,143 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestDryRun(t *testing.T) {
+	fileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "var = {{.var}}"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy:
+			template1:
+				type: file
+				data: "var = 2"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									var: 3
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	expectedLinkMessage := `
+		Link "link1" would be created:
+			target: "{Root}/configs/link.conf"
+			link: "{Root}/deploy/link1"
+	`
+
+	expectedTemplateMessage := `
+		Template "template1" would be replaced:
+			input: "{Root}/configs/template.conf"
+			output: "{Root}/deploy/template1"
+	`
+
+	expectedCommandMessage := `
+		Command "command1" would execute:
+			command: cat {Root}/configs/command.conf > {Root}/deploy/command1
+	`
+
+	t.Run("FlagBeforeInstance", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "--dry-run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, fileTree)
+		c.RequireSuccessMessage(t, expectedLinkMessage)
+		c.RequireSuccessMessage(t, expectedTemplateMessage)
+		c.RequireSuccessMessage(t, expectedCommandMessage)
+	})
+
+	t.Run("FlagAfterInstance", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "pc1", "--dry-run")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, fileTree)
+		c.RequireSuccessMessage(t, expectedLinkMessage)
+	})
+
+	t.Run("DeployedCommandIsSkipped", func(t *testing.T) {
+		fileTree := `
+			.git:
+			data.txt:
+				type: file
+				data: some data
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						pc1:
+							commands:
+								data-rev:
+									input: "{{.GitRoot}}/data.txt"
+									output: "{{.GitRoot}}/data-rev.txt"
+									command: "rev {{.Input}} > {{.Output}}"
+		`
+
+		c := testcase.RunCase(t, fileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		c.Rerun("./run", "--dry-run", "-v", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireVerboseMessage(t, `Command "data-rev" is skipped`)
+	})
+
+	t.Run("Fail", func(t *testing.T) {
+		fileTree := `
+			.git:
+			link.conf:
+				type: file
+			link1:
+				type: file
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						pc1:
+							links:
+								link1:
+									target: "{{.GitRoot}}/link.conf"
+									link: "{{.GitRoot}}/link1"
+		`
+
+		expectedMessage := `
+			Unable to create "link1" link:
+				target: "{Root}/link.conf"
+				link: "{Root}/link1"
+					error: link path is occupied
+		`
+
+		c := testcase.RunCase(t, fileTree, "./run", "--dry-run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFileTree(t, fileTree)
+		c.RequireFailMessage(t, expectedMessage)
+	})
+}
+
+func TestUnknownFlag(t *testing.T) {
+	c := testcase.RunCase(t, "", "./run", "--unknown", "pc1")
+
+	c.RequireReturnCode(t, 1)
+	c.RequireFailMessage(t, "flag provided but not defined")
+}
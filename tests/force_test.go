@@ -0,0 +1,105 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestModifiedOutputProtection(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			template.conf:
+				type: file
+				data: "var = {{.var}}"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									var: 3
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	// modifyOutputs edits deployed outputs by hand.
+	modifyOutputs := func(t *testing.T, c *testcase.TestCase) {
+		c.RemovePaths(t, "deploy/template1", "deploy/command1")
+		c.AddFileTree(t, `
+			deploy:
+				template1:
+					type: file
+					data: "var = 4"
+				command1:
+					type: file
+					data: "other data"
+		`)
+	}
+
+	t.Run("ModifiedOutputsAreProtected", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		modifyOutputs(t, &c)
+
+		c.Rerun("./run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, `Unable to expand "template1" template`)
+		c.RequireFailMessage(t, `Unable to execute "command1" command`)
+		c.RequireFailMessage(t, "modified after deploying")
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				template.conf:
+					type: file
+				command.conf:
+					type: file
+			deploy:
+				template1:
+					type: file
+					data: "var = 4"
+				command1:
+					type: file
+					data: "other data"
+			deploy-configs.yaml:
+				type: file
+		`)
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		modifyOutputs(t, &c)
+
+		c.Rerun("./run", "--force", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				template.conf:
+					type: file
+				command.conf:
+					type: file
+			deploy:
+				template1:
+					type: file
+					data: "var = 3"
+				command1:
+					type: file
+					data: "some data"
+			deploy-configs.yaml:
+				type: file
+		`)
+	})
+}
@@ -1,6 +1,9 @@
 package tests_test
 
 import (
+	"fmt"
+	"path"
+	"strings"
 	"testing"
 
 	"github.com/backdround/deploy-configs/tests/testcase"
@@ -302,10 +305,10 @@ func TestAlphabeticalExecutionSequence(t *testing.T) {
 				`Link "link2" is skipped`,
 				`Link "link3" is skipped`,
 			}
-			c := testcase.RunCase(t, fileTree, "./run", "pc1")
+			c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 			c.RequireReturnCode(t, 0)
 			c.RequireFileTree(t, fileTree)
-			c.RequireLogMessages(t, expectedMessages, 2)
+			c.RequireVerboseMessages(t, expectedMessages, 2)
 	})
 
 	t.Run("linkDirectory", func(t *testing.T) {
@@ -343,10 +346,10 @@ func TestAlphabeticalExecutionSequence(t *testing.T) {
 				`Link "sources/link2" is skipped`,
 				`Link "sources/link3" is skipped`,
 			}
-			c := testcase.RunCase(t, fileTree, "./run", "pc1")
+			c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 			c.RequireReturnCode(t, 0)
 			c.RequireFileTree(t, fileTree)
-			c.RequireLogMessages(t, expectedMessages, 2)
+			c.RequireVerboseMessages(t, expectedMessages, 2)
 	})
 
 	t.Run("Commands", func(t *testing.T) {
@@ -383,15 +386,21 @@ func TestAlphabeticalExecutionSequence(t *testing.T) {
 										output: "{{.GitRoot}}/rev3.txt"
 										command: "rev {{.Input}} > {{.Output}}"
 			`
-			expectedMessages := []string{
-				`Command "reverse1" is skipped`,
-				`Command "reverse2" is skipped`,
-				`Command "reverse3" is skipped`,
-			}
-			c := testcase.RunCase(t, fileTree, "./run", "pc1")
+			c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 			c.RequireReturnCode(t, 0)
 			c.RequireFileTree(t, fileTree)
-			c.RequireLogMessages(t, expectedMessages, 2)
+
+			expectedMessages := []string{}
+			for _, name := range []string{"reverse1", "reverse2", "reverse3"} {
+				output := strings.Replace(name, "reverse", "rev", 1) + ".txt"
+				expectedMessages = append(expectedMessages,
+					fmt.Sprintf("Command %q executes:\n  rev %v > %v", name,
+						path.Join(c.Root(), "data.txt"),
+						path.Join(c.Root(), ".deploy-configs-*", output)),
+					fmt.Sprintf("Command %q is skipped", name),
+				)
+			}
+			c.RequireVerboseMessages(t, expectedMessages, 2)
 	})
 
 	t.Run("Templates", func(t *testing.T) {
@@ -436,9 +445,9 @@ func TestAlphabeticalExecutionSequence(t *testing.T) {
 				`Template "template2" is skipped`,
 				`Template "template3" is skipped`,
 			}
-			c := testcase.RunCase(t, fileTree, "./run", "pc1")
+			c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 			c.RequireReturnCode(t, 0)
 			c.RequireFileTree(t, fileTree)
-			c.RequireLogMessages(t, expectedMessages, 2)
+			c.RequireVerboseMessages(t, expectedMessages, 2)
 	})
 }
@@ -0,0 +1,49 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestInclude(t *testing.T) {
+	initialFileTree := `
+		.git:
+		tmux:
+			tmux.conf:
+				type: file
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						pc1:
+							links:
+								tmux:
+									target: ./tmux.conf
+									link: "{{.GitRoot}}/deploy/tmux.conf"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				include:
+					- "*/deploy-configs.yaml"
+				instances:
+					pc1:
+	`
+
+	c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+	c.RequireReturnCode(t, 0)
+	c.RequireFileTree(t, `
+		.git:
+		tmux:
+			tmux.conf:
+				type: file
+			deploy-configs.yaml:
+				type: file
+		deploy:
+			tmux.conf:
+				type: link
+				path: ../tmux/tmux.conf
+		deploy-configs.yaml:
+			type: file
+	`)
+}
@@ -0,0 +1,174 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestMultipleInstances(t *testing.T) {
+	t.Run("InstancesAreDeployed", func(t *testing.T) {
+		initialFileTree := `
+			.git:
+			configs:
+				base.conf:
+					type: file
+				desktop.conf:
+					type: file
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						base:
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/base.conf"
+									link: "{{.GitRoot}}/deploy/base"
+						desktop:
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/desktop.conf"
+									link: "{{.GitRoot}}/deploy/desktop"
+		`
+
+		c := testcase.RunCase(t, initialFileTree, "./run", "base", "desktop")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				base.conf:
+					type: file
+				desktop.conf:
+					type: file
+			deploy:
+				base:
+					type: link
+					path: ../configs/base.conf
+				desktop:
+					type: link
+					path: ../configs/desktop.conf
+			deploy-configs.yaml:
+				type: file
+		`)
+
+		require.Len(t, c.ReadManifest(t, "base").Links, 1)
+		require.Len(t, c.ReadManifest(t, "desktop").Links, 1)
+	})
+
+	t.Run("ConflictsAreDetected", func(t *testing.T) {
+		initialFileTree := `
+			.git:
+			configs:
+				base.conf:
+					type: file
+				desktop.conf:
+					type: file
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						base:
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/base.conf"
+									link: "{{.GitRoot}}/deploy/config"
+						desktop:
+							links:
+								link2:
+									target: "{{.GitRoot}}/configs/desktop.conf"
+									link: "{{.GitRoot}}/deploy/config"
+		`
+
+		c := testcase.RunCase(t, initialFileTree, "./run", "base", "desktop")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "Instances have conflicting outputs:")
+		c.RequireFailMessage(t, `
+			"{Root}/deploy/config":
+				base link "link1"
+				desktop link "link2"
+		`)
+		c.RequireFileTree(t, initialFileTree)
+	})
+
+	t.Run("ParentAndChildAreDeployedTogether", func(t *testing.T) {
+		initialFileTree := `
+			.git:
+			configs:
+				base.conf:
+					type: file
+				laptop.conf:
+					type: file
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						base:
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/base.conf"
+									link: "{{.GitRoot}}/deploy/base"
+						laptop:
+							extends: [base]
+							links:
+								link2:
+									target: "{{.GitRoot}}/configs/laptop.conf"
+									link: "{{.GitRoot}}/deploy/laptop"
+		`
+
+		c := testcase.RunCase(t, initialFileTree, "./run", "base", "laptop")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				base.conf:
+					type: file
+				laptop.conf:
+					type: file
+			deploy:
+				base:
+					type: link
+					path: ../configs/base.conf
+				laptop:
+					type: link
+					path: ../configs/laptop.conf
+			deploy-configs.yaml:
+				type: file
+		`)
+		// The inherited link is owned by the instance that created it
+		require.Len(t, c.ReadManifest(t, "base").Links, 1)
+		require.Len(t, c.ReadManifest(t, "laptop").Links, 1)
+	})
+
+	t.Run("OverriddenInheritedUnitsConflict", func(t *testing.T) {
+		initialFileTree := `
+			.git:
+			configs:
+				base.conf:
+					type: file
+				laptop.conf:
+					type: file
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						base:
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/base.conf"
+									link: "{{.GitRoot}}/deploy/config"
+						laptop:
+							extends: [base]
+							links:
+								link1:
+									target: "{{.GitRoot}}/configs/laptop.conf"
+									link: "{{.GitRoot}}/deploy/config"
+		`
+
+		c := testcase.RunCase(t, initialFileTree, "./run", "base", "laptop")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "Instances have conflicting outputs:")
+		c.RequireFileTree(t, initialFileTree)
+	})
+}
@@ -0,0 +1,55 @@
+package tests_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestInteractive(t *testing.T) {
+	fileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+		deploy:
+			link1:
+				type: link
+				path: /dev/null
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+	`
+
+	// Makes the run non-interactive
+	stdin, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	defer stdin.Close()
+
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = oldStdin }()
+
+	t.Run("RefusesWithoutTerminal", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "--interactive", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "--interactive requires a terminal")
+		c.RequireFileTree(t, fileTree)
+	})
+
+	t.Run("JsonOutput", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "--interactive",
+			"--output", "json", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "--interactive can't be used with --output json")
+	})
+}
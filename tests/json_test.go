@@ -0,0 +1,47 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestJsonOutput(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+		deploy:
+			link2:
+				type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+							link2:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+	`
+
+	c := testcase.RunCase(t, initialFileTree, "./run", "--output", "json",
+		"pc1")
+	c.RequireReturnCode(t, 1)
+
+	c.RequireLogMessage(t, `{"type":"message","level":"title",`+
+		`"message":"Create links"}`)
+	c.RequireLogMessage(t, `{"type":"unit","instance":"pc1","kind":"link",`+
+		`"name":"link1","action":"created",`+
+		`"source":"{Root}/configs/link.conf",`+
+		`"destination":"{Root}/deploy/link1","duration":`)
+	c.RequireLogMessage(t, `"name":"link2","action":"failed",`)
+	c.RequireLogMessage(t, `"error":"link path is occupied`)
+	c.RequireLogMessage(t, `{"type":"summary","command":"deploy",`+
+		`"instances":["pc1"],"actions":{"created":1,"failed":1},`+
+		`"return_code":1}`)
+}
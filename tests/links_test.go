@@ -102,10 +102,10 @@ func TestLinks(t *testing.T) {
 										link: "{{.GitRoot}}/link1"
 			`
 
-		c := testcase.RunCase(t, fileTree, "./run", "pc1")
+		c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 		c.RequireReturnCode(t, 0)
 		c.RequireFileTree(t, fileTree)
-		c.RequireLogMessage(t, `Link "link1" is skipped`)
+		c.RequireVerboseMessage(t, `Link "link1" is skipped`)
 	})
 
 	t.Run("Fail", func(t *testing.T) {
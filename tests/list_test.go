@@ -0,0 +1,65 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestList(t *testing.T) {
+	initialFileTree := `
+		.git:
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc2:
+						links:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	t.Run("Instances", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "list")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessages(t, []string{"pc1", "pc2"}, 0)
+	})
+
+	t.Run("InstancesJson", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "list",
+			"--output", "json")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessages(t, []string{"[\n  \"pc1\",\n  \"pc2\"\n]"}, 0)
+	})
+
+	t.Run("UnitsTable", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "list", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessage(t, "INSTANCE  KIND     NAME      SOURCE")
+		c.RequireLogMessage(t, "pc1       link     link1     {Root}/configs/link.conf")
+		c.RequireLogMessage(t, "pc1       command  command1  {Root}/configs/command.conf")
+	})
+
+	t.Run("UnitsJson", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "list", "pc1",
+			"--output", "json")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessage(t, `"kind": "link",`)
+		c.RequireLogMessage(t, `"destination": "{Root}/deploy/command1",`)
+		c.RequireLogMessage(t, `"command": "cat {{.Input}} > {{.Output}}"`)
+	})
+
+	t.Run("UnknownInstance", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "list", "pc3")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, `There is no instance "pc3" in [pc1, pc2]`)
+	})
+}
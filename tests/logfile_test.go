@@ -0,0 +1,72 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestLogFile(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			data.txt:
+				type: file
+				data: some data
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/data.txt"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "echo failure >&2 && cat {{.Input}} > {{.Output}}"
+	`
+
+	t.Run("FullLogIsAppended", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "-q", "--log-file",
+			"pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireNoSuccessMessages(t)
+
+		c.Rerun("./run", "--log-file", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		log := c.ReadLogFile(t)
+		require.Contains(t, log, "[title] Run: run -q --log-file pc1")
+		require.Contains(t, log, `[ok] Link "link1" created:`)
+		require.Contains(t, log, "[title] Run: run --log-file pc1")
+		require.Contains(t, log, `[skip] Link "link1" is skipped`)
+		require.Contains(t, log, "[debug]   failure")
+		require.NotContains(t, log, "\x1b[")
+	})
+
+	t.Run("JsonResultsAreLogged", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "--output", "json",
+			"--log-file", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		log := c.ReadLogFile(t)
+		require.Contains(t, log, `[ok] Link "link1" created:`)
+		require.Contains(t, log, `[log] {"type":"unit","instance":"pc1",`+
+			`"kind":"link","name":"link1","action":"created",`)
+		require.Contains(t, log, `[log] {"type":"summary","command":"deploy",`)
+		require.NotContains(t, log, `"type":"message"`)
+	})
+
+	t.Run("LogFileIsOptional", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		require.NoFileExists(t, c.LogFilePath())
+	})
+}
@@ -0,0 +1,77 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/pkg/fsutility"
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestManifest(t *testing.T) {
+	fileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "var = {{.var}}"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+							link2:
+								target: "{{.GitRoot}}/configs/absent.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									var: 3
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	t.Run("RecordsDeployedUnits", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 1)
+
+		m := c.ReadManifest(t, "pc1")
+		require.Equal(t, "pc1", m.Instance)
+
+		require.Len(t, m.Links, 1)
+		require.Equal(t, "link1", m.Links[0].Name)
+		require.Equal(t, c.Root()+"/configs/link.conf", m.Links[0].TargetPath)
+		require.Equal(t, c.Root()+"/deploy/link1", m.Links[0].LinkPath)
+		require.False(t, m.Links[0].DeployedAt.IsZero())
+
+		require.Len(t, m.Templates, 1)
+		require.Equal(t, c.Root()+"/deploy/template1", m.Templates[0].OutputPath)
+		require.Equal(t, fsutility.GetHash([]byte("var = 3")),
+			m.Templates[0].Hash)
+
+		require.Len(t, m.Commands, 1)
+		require.Equal(t, c.Root()+"/deploy/command1", m.Commands[0].OutputPath)
+		require.Equal(t, fsutility.GetHash([]byte("some data")),
+			m.Commands[0].Hash)
+	})
+
+	t.Run("DryRunDoesntWriteManifest", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "--dry-run", "pc1")
+		require.NoFileExists(t, c.ManifestPath("pc1"))
+	})
+}
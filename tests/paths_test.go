@@ -0,0 +1,88 @@
+package tests_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestPathResolution(t *testing.T) {
+	initialFileTree := `
+		.git:
+		subdirectory:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "some data"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: ./configs/link.conf
+								link: ./deploy/link1
+							link2:
+								target: configs/link.conf
+								link: ~/link2
+						templates:
+							template1:
+								input: ./configs/template.conf
+								output: ./deploy/template1
+								data:
+						commands:
+							command1:
+								input: ./configs/command.conf
+								output: ./deploy/command1
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Checks the status to create the test directory
+	c := testcase.RunCase(t, initialFileTree, "./run", "status", "pc1")
+	c.RequireReturnCode(t, 1)
+
+	// Deploys from the subdirectory
+	c.Chdir(t, "subdirectory")
+	c.Rerun("./run", "pc1")
+	c.RequireReturnCode(t, 0)
+	c.RequireFileTree(t, `
+		.git:
+		subdirectory:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+			command.conf:
+				type: file
+		deploy:
+			link1:
+				type: link
+				path: ../configs/link.conf
+			template1:
+				type: file
+				data: "some data"
+			command1:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+	`)
+
+	target, err := os.Readlink(path.Join(home, "link2"))
+	require.NoError(t, err)
+	require.Equal(t, path.Join(c.Root(), "configs/link.conf"), target)
+}
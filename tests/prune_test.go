@@ -0,0 +1,131 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestPrune(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			directory:
+				a.conf:
+					type: file
+				b.conf:
+					type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+							directory:
+								target: "{{.GitRoot}}/configs/directory"
+								link: "{{.GitRoot}}/deploy/directory"
+	`
+
+	// changeConfig removes link1 from the config and b.conf from the
+	// linked directory.
+	changeConfig := func(t *testing.T, c *testcase.TestCase) {
+		c.RemovePaths(t, "deploy-configs.yaml", "configs/directory/b.conf")
+		c.AddFileTree(t, `
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						pc1:
+							links:
+								directory:
+									target: "{{.GitRoot}}/configs/directory"
+									link: "{{.GitRoot}}/deploy/directory"
+		`)
+	}
+
+	t.Run("RemovesStaleLinks", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		changeConfig(t, &c)
+
+		c.Rerun("./run", "--prune", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				link.conf:
+					type: file
+				directory:
+					a.conf:
+						type: file
+			deploy:
+				directory:
+					a.conf:
+						type: link
+						path: ../../configs/directory/a.conf
+			deploy-configs.yaml:
+				type: file
+		`)
+		c.RequireSuccessMessage(t, `
+			Link "link1" pruned:
+				target: "{Root}/configs/link.conf"
+				link: "{Root}/deploy/link1"
+		`)
+		c.RequireSuccessMessage(t, `Link "directory/b.conf" pruned`)
+
+		m := c.ReadManifest(t, "pc1")
+		require.Len(t, m.Links, 1)
+		require.Equal(t, "directory/a.conf", m.Links[0].Name)
+	})
+
+	t.Run("ForgetsReplacedStaleLinks", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		changeConfig(t, &c)
+
+		// Replaces the stale link with a user file
+		c.RemovePaths(t, "deploy/link1")
+		c.AddFileTree(t, `
+			deploy:
+				link1:
+					type: file
+		`)
+
+		c.Rerun("./run", "--prune", "pc1")
+		c.RequireReturnCode(t, 0)
+		require.FileExists(t, c.Root()+"/deploy/link1")
+
+		// Asserts that the link isn't pruned again
+		m := c.ReadManifest(t, "pc1")
+		require.Len(t, m.Links, 1)
+		require.Equal(t, "directory/a.conf", m.Links[0].Name)
+	})
+
+	t.Run("LeavesStaleLinksWithoutFlag", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		changeConfig(t, &c)
+
+		c.Rerun("./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		require.FileExists(t, c.Root()+"/deploy/link1")
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		changeConfig(t, &c)
+
+		c.Rerun("./run", "--prune", "--dry-run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Link "link1" would be pruned`)
+		require.FileExists(t, c.Root()+"/deploy/link1")
+	})
+}
@@ -0,0 +1,73 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestInstanceSelection(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					selected:
+						select:
+							env: DEPLOY_CONFIGS_TEST_MACHINE
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+					other:
+						select:
+							env: DEPLOY_CONFIGS_TEST_OTHER_MACHINE
+						links:
+							link2:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+	`
+
+	t.Run("MatchingInstanceIsDeployed", func(t *testing.T) {
+		t.Setenv("DEPLOY_CONFIGS_TEST_MACHINE", "1")
+
+		c := testcase.RunCase(t, initialFileTree, "./run")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, "Selected instances: selected")
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				link.conf:
+					type: file
+			deploy:
+				link1:
+					type: link
+					path: ../configs/link.conf
+			deploy-configs.yaml:
+				type: file
+		`)
+	})
+
+	t.Run("CommandWithoutInstances", func(t *testing.T) {
+		t.Setenv("DEPLOY_CONFIGS_TEST_MACHINE", "1")
+
+		c := testcase.RunCase(t, initialFileTree, "./run")
+		c.RequireReturnCode(t, 0)
+
+		c.Rerun("./run", "status")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, "Selected instances: selected")
+		c.RequireLogMessage(t, `Link "link1" is in sync`)
+	})
+
+	t.Run("NothingMatches", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "There is no instance that matches")
+		c.RequireFileTree(t, initialFileTree)
+	})
+}
@@ -0,0 +1,126 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestStatus(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "var = {{.var}}"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+							link2:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									var: 3
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	t.Run("InSync", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		c.Rerun("./run", "status", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessage(t, `Link "link1" is in sync`)
+		c.RequireLogMessage(t, `Template "template1" is in sync`)
+		c.RequireLogMessage(t, `Command "command1" output is in sync`)
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		// Changes deployed units
+		c.RemovePaths(t, "deploy/link1", "deploy/link2", "deploy/template1",
+			"deploy/command1")
+		c.AddFileTree(t, `
+			deploy:
+				link2:
+					type: link
+					path: ../configs/template.conf
+				template1:
+					type: file
+					data: "var = 4"
+				command1:
+					type: file
+					data: "other data"
+		`)
+
+		c.Rerun("./run", "status", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireWarnMessage(t, `
+			Link "link1" is missing:
+				target: "{Root}/configs/link.conf"
+				link: "{Root}/deploy/link1"
+		`)
+		c.RequireWarnMessage(t, `Link "link2" is pointing elsewhere`)
+		c.RequireWarnMessage(t, `Template "template1" is locally modified`)
+		c.RequireWarnMessage(t, `Command "command1" output is locally modified`)
+	})
+
+	t.Run("Outdated", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		// Changes sources of the deployed units
+		c.RemovePaths(t, "configs/template.conf", "configs/command.conf")
+		c.AddFileTree(t, `
+			configs:
+				template.conf:
+					type: file
+					data: "new var = {{.var}}"
+				command.conf:
+					type: file
+					data: "new data"
+		`)
+
+		c.Rerun("./run", "status", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireWarnMessage(t, `Template "template1" is outdated`)
+		c.RequireWarnMessage(t, `Command "command1" output is outdated`)
+	})
+
+	t.Run("Occupied", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		c.RemovePaths(t, "deploy/link1")
+		c.AddFileTree(t, `
+			deploy:
+				link1:
+					type: file
+		`)
+
+		c.Rerun("./run", "status", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireWarnMessage(t, `Link "link1" is occupied`)
+	})
+}
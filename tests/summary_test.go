@@ -0,0 +1,75 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestSummary(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "{{.var}}"
+		deploy:
+			link2:
+				type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+							link2:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+							template2:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template2"
+								data:
+									var: 3
+	`
+
+	t.Run("Failures", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "created  replaced  skipped  failed  removed\n"+
+			"links      1        0         0        1       0\n"+
+			"templates  1        0         0        1       0\n"+
+			"commands   0        0         0        0       0\n"+
+			"Total time: ")
+		c.RequireFailMessage(t, `pc1: link "link2"`)
+		c.RequireFailMessage(t, `pc1: template "template1"`)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 1)
+
+		// Fixes the failures
+		c.RemovePaths(t, "deploy/link2", "configs/template.conf")
+		c.AddFileTree(t, `
+			configs:
+				template.conf:
+					type: file
+					data: "some data"
+		`)
+
+		c.Rerun("./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, "links      1        0         1        0")
+		c.RequireSuccessMessage(t, "templates  1        1         0        0")
+	})
+}
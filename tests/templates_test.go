@@ -114,10 +114,10 @@ func TestTemplates(t *testing.T) {
 										var: 3
 		`
 
-		c := testcase.RunCase(t, fileTree, "./run", "pc1")
+		c := testcase.RunCase(t, fileTree, "./run", "-v", "pc1")
 		c.RequireReturnCode(t, 0)
 		c.RequireFileTree(t, fileTree)
-		c.RequireLogMessage(t, `Template "config" is skipped`)
+		c.RequireVerboseMessage(t, `Template "config" is skipped`)
 	})
 
 	t.Run("Fail", func(t *testing.T) {
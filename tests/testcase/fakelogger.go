@@ -2,6 +2,7 @@ package testcase
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -14,8 +15,14 @@ type FakeLogger struct {
 	warns     []string
 	fails     []string
 	logs      []string
+	verboses  []string
+	debugs    []string
 }
 
+// temporaryDirectory matches random names of temporary directories
+// where outputs are made before they are moved to their places.
+var temporaryDirectory = regexp.MustCompile(`\.deploy-configs-[0-9]+/`)
+
 ////////////////////////////////////////////////////////////
 // Implement logger
 
@@ -39,6 +46,24 @@ func (l *FakeLogger) Log(message string) {
 	l.logs = append(l.logs, message)
 }
 
+// Skip records skips as verbose messages, because they are logged only
+// in the verbose mode.
+func (l *FakeLogger) Skip(message string) {
+	l.verboses = append(l.verboses, message)
+}
+
+// Verbose records verbose messages with ".deploy-configs-*/" in place of
+// temporary directories, so executed commands can be compared.
+func (l *FakeLogger) Verbose(message string) {
+	message = temporaryDirectory.ReplaceAllString(message,
+		".deploy-configs-*/")
+	l.verboses = append(l.verboses, message)
+}
+
+func (l *FakeLogger) Debug(message string) {
+	l.debugs = append(l.debugs, message)
+}
+
 ////////////////////////////////////////////////////////////
 // Test utility members
 
@@ -82,3 +107,30 @@ func (l *FakeLogger) RequireLogEqual(t *testing.T, messages []string,
 	t.Helper()
 	require.Equal(t, messages, l.logs[skipCount:])
 }
+
+func (l *FakeLogger) RequireVerboseContains(t *testing.T, message string) {
+	t.Helper()
+	l.requireContains(t, l.verboses, message)
+}
+
+func (l *FakeLogger) RequireVerboseEqual(t *testing.T, messages []string,
+	skipCount int) {
+	t.Helper()
+	require.Equal(t, messages, l.verboses[skipCount:])
+}
+
+func (l *FakeLogger) RequireDebugContains(t *testing.T, message string) {
+	t.Helper()
+	l.requireContains(t, l.debugs, message)
+}
+
+func (l *FakeLogger) RequireNoVerbose(t *testing.T) {
+	t.Helper()
+	require.Empty(t, l.verboses)
+}
+
+func (l *FakeLogger) RequireNoSuccess(t *testing.T) {
+	t.Helper()
+	require.Empty(t, l.titles)
+	require.Empty(t, l.successes)
+}
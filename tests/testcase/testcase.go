@@ -3,6 +3,7 @@ package testcase
 
 import (
 	"os"
+	"path"
 	"regexp"
 	"strings"
 	"testing"
@@ -11,13 +12,15 @@ import (
 	"github.com/lithammer/dedent"
 	"github.com/stretchr/testify/require"
 
+	"github.com/backdround/deploy-configs/internal/manifest"
 	"github.com/backdround/deploy-configs/internal/realmain"
 )
 
 type TestCase struct {
-	returnCode    int
-	fakeLogger    *FakeLogger
-	testDirectory string
+	returnCode     int
+	fakeLogger     *FakeLogger
+	testDirectory  string
+	stateDirectory string
 }
 
 func RunCase(t *testing.T, fileTreeYaml string, arguments ...string) TestCase {
@@ -33,6 +36,42 @@ func RunCase(t *testing.T, fileTreeYaml string, arguments ...string) TestCase {
 ////////////////////////////////////////////////////////////
 // Public fucntions
 
+// Rerun runs the application again over the current test directory.
+// It resets all logged messages.
+func (c *TestCase) Rerun(arguments ...string) {
+	c.fakeLogger = &FakeLogger{}
+	c.returnCode = realmain.Main(c.fakeLogger, arguments)
+}
+
+// AddFileTree creates the file tree in the test directory. Existing
+// entries have to be removed before.
+func (c *TestCase) AddFileTree(t *testing.T, fileTreeYaml string) {
+	t.Helper()
+
+	fileTreeYaml = c.prepareYaml(fileTreeYaml)
+	err := fstree.MakeOverOSFS(c.testDirectory, fileTreeYaml)
+	require.NoError(t, err)
+}
+
+// RemovePaths removes the paths that are relative to the test directory.
+func (c *TestCase) RemovePaths(t *testing.T, relativePaths ...string) {
+	t.Helper()
+
+	for _, relativePath := range relativePaths {
+		err := os.RemoveAll(path.Join(c.testDirectory, relativePath))
+		require.NoError(t, err)
+	}
+}
+
+// Chdir changes the work directory to the path that is relative to
+// the test directory.
+func (c *TestCase) Chdir(t *testing.T, relativePath string) {
+	t.Helper()
+
+	err := os.Chdir(path.Join(c.testDirectory, relativePath))
+	require.NoError(t, err)
+}
+
 func (c *TestCase) RequireFileTree(t *testing.T, fileTreeYaml string) {
 	t.Helper()
 
@@ -55,6 +94,12 @@ func (c *TestCase) RequireFailMessage(t *testing.T, message string) {
 	c.fakeLogger.RequireFailContains(t, message)
 }
 
+func (c *TestCase) RequireWarnMessage(t *testing.T, message string) {
+	t.Helper()
+	message = c.prepareOutput(message)
+	c.fakeLogger.RequireWarnContains(t, message)
+}
+
 func (c *TestCase) RequireSuccessMessage(t *testing.T, message string) {
 	t.Helper()
 	message = c.prepareOutput(message)
@@ -73,6 +118,75 @@ func (c *TestCase) RequireLogMessages(t *testing.T, messages []string,
 	c.fakeLogger.RequireLogEqual(t, messages, skipCount)
 }
 
+func (c *TestCase) RequireVerboseMessage(t *testing.T, message string) {
+	t.Helper()
+	message = c.prepareOutput(message)
+	c.fakeLogger.RequireVerboseContains(t, message)
+}
+
+func (c *TestCase) RequireVerboseMessages(t *testing.T, messages []string,
+	skipCount int) {
+	t.Helper()
+	c.fakeLogger.RequireVerboseEqual(t, messages, skipCount)
+}
+
+func (c *TestCase) RequireDebugMessage(t *testing.T, message string) {
+	t.Helper()
+	message = c.prepareOutput(message)
+	c.fakeLogger.RequireDebugContains(t, message)
+}
+
+func (c *TestCase) RequireNoVerboseMessages(t *testing.T) {
+	t.Helper()
+	c.fakeLogger.RequireNoVerbose(t)
+}
+
+func (c *TestCase) RequireNoSuccessMessages(t *testing.T) {
+	t.Helper()
+	c.fakeLogger.RequireNoSuccess(t)
+}
+
+// Root returns a path to the test directory.
+func (c *TestCase) Root() string {
+	return c.testDirectory
+}
+
+// ReadManifest returns the deployment manifest of the given instance.
+func (c *TestCase) ReadManifest(t *testing.T, instance string) *manifest.Manifest {
+	t.Helper()
+
+	manifestPath, err := manifest.GetPath(instance)
+	assertNoError(err)
+	require.FileExists(t, manifestPath)
+
+	m, err := manifest.Load(manifestPath, instance)
+	require.NoError(t, err)
+	return m
+}
+
+// ManifestPath returns a path to the deployment manifest of the instance.
+func (c *TestCase) ManifestPath(instance string) string {
+	manifestPath, err := manifest.GetPath(instance)
+	assertNoError(err)
+	return manifestPath
+}
+
+// LogFilePath returns a path to the log file of all runs.
+func (c *TestCase) LogFilePath() string {
+	logPath, err := manifest.GetLogPath()
+	assertNoError(err)
+	return logPath
+}
+
+// ReadLogFile returns the content of the log file of all runs.
+func (c *TestCase) ReadLogFile(t *testing.T) string {
+	t.Helper()
+
+	data, err := os.ReadFile(c.LogFilePath())
+	require.NoError(t, err)
+	return string(data)
+}
+
 ////////////////////////////////////////////////////////////
 // Private fucntions
 
@@ -106,6 +220,10 @@ func (c *TestCase) prepareTestEnvirenment(t *testing.T, fileTreeYaml string) {
 	c.testDirectory, err = os.MkdirTemp("", "go-test-deploy-configs-*.d")
 	assertNoError(err)
 
+	// Isolates the application state from the user state
+	c.stateDirectory = t.TempDir()
+	t.Setenv("XDG_STATE_HOME", c.stateDirectory)
+
 	// Cd to test directory
 	err = os.Chdir(c.testDirectory)
 	assertNoError(err)
@@ -0,0 +1,126 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestUndeploy(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "var = {{.var}}"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy:
+			user-file:
+				type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/links/link1"
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									var: 3
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/generated/command1"
+								command: "cat {{.Input}} > {{.Output}}"
+	`
+
+	t.Run("RemovesDeployedUnits", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		c.Rerun("./run", "undeploy", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, initialFileTree)
+		c.RequireSuccessMessage(t, `
+			Link "link1" removed:
+				target: "{Root}/configs/link.conf"
+				link: "{Root}/deploy/links/link1"
+		`)
+		c.RequireSuccessMessage(t, `Template "template1" output removed`)
+		c.RequireSuccessMessage(t, `Command "command1" output removed`)
+		c.RequireSuccessMessage(t, `Directory "{Root}/generated" removed`)
+		require.NoFileExists(t, c.ManifestPath("pc1"))
+	})
+
+	t.Run("LeavesChangedUnits", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		// Changes deployed units
+		c.RemovePaths(t, "deploy/links/link1", "deploy/template1")
+		c.AddFileTree(t, `
+			deploy:
+				links:
+					link1:
+						type: link
+						path: ../../configs/template.conf
+				template1:
+					type: file
+					data: "var = 4"
+		`)
+
+		c.Rerun("./run", "undeploy", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				link.conf:
+					type: file
+				template.conf:
+					type: file
+				command.conf:
+					type: file
+			deploy:
+				links:
+					link1:
+						type: link
+						path: ../../configs/template.conf
+				template1:
+					type: file
+					data: "var = 4"
+				user-file:
+					type: file
+			deploy-configs.yaml:
+				type: file
+		`)
+		c.RequireWarnMessage(t, `Link "link1" is left`)
+		c.RequireWarnMessage(t, `Template "template1" output is left`)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+
+		c.Rerun("./run", "undeploy", "--dry-run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, `Link "link1" would be removed`)
+		require.FileExists(t, c.ManifestPath("pc1"))
+	})
+
+	t.Run("InstanceIsntDeployed", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "undeploy", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireWarnMessage(t, `Instance "pc1" isn't deployed`)
+	})
+}
@@ -0,0 +1,73 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestVariables(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "{{.Vars.editor}} {{.theme}}"
+			command.conf:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				variables:
+					configs: configs
+					editor: vim
+				instances:
+					pc1:
+						variables:
+							deploy: deploy
+							editor: nvim
+						links:
+							link1:
+								target: "{{.GitRoot}}/{{.Vars.configs}}/link.conf"
+								link: "{{.GitRoot}}/{{.Vars.deploy}}/link1"
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+									theme: dark
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/command.conf"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "echo {{.Vars.editor}} > {{.Output}}"
+	`
+
+	c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+	c.RequireReturnCode(t, 0)
+	c.RequireFileTree(t, `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+			command.conf:
+				type: file
+		deploy:
+			link1:
+				type: link
+				path: ../configs/link.conf
+			template1:
+				type: file
+				data: "nvim dark"
+			command1:
+				type: file
+				data: "nvim\n"
+		deploy-configs.yaml:
+			type: file
+	`)
+}
@@ -0,0 +1,121 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestVerbosity(t *testing.T) {
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			data.txt:
+				type: file
+				data: some data
+		deploy:
+			link2:
+				type: file
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+							link2:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+						commands:
+							command1:
+								input: "{{.GitRoot}}/configs/data.txt"
+								output: "{{.GitRoot}}/deploy/command1"
+								command: "echo echoed && cat {{.Input}} > {{.Output}}"
+	`
+
+	t.Run("Quiet", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "-q", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireNoSuccessMessages(t)
+		c.RequireNoVerboseMessages(t)
+		c.RequireFailMessage(t, `Unable to create "link2" link`)
+	})
+
+	t.Run("QuietCommandOutput", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "-q", "list", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessage(t, "pc1       link     link1     {Root}/configs/link.conf")
+
+		c.Rerun("./run", "-q", "diff", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireNoSuccessMessages(t)
+		c.RequireLogMessage(t, `
+			@@ -0,0 +1 @@
+			+some data
+		`)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireSuccessMessage(t, `Link "link1" created`)
+		c.RequireNoVerboseMessages(t)
+
+		c.Rerun("./run", "pc1")
+		c.RequireNoVerboseMessages(t)
+	})
+
+	t.Run("Verbose", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.Rerun("./run", "-v", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireVerboseMessage(t, "GitRoot: {Root}")
+		c.RequireVerboseMessage(t, `Link "link1" is skipped`)
+		c.RequireVerboseMessage(t, `
+			Command "command1" executes:
+				echo echoed && cat {Root}/configs/data.txt > {Root}/deploy/.deploy-configs-*/command1
+		`)
+	})
+
+	t.Run("Debug", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "-vv", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireDebugMessage(t, `
+			Command "command1" output:
+				echoed
+		`)
+	})
+
+	t.Run("QuietWithVerbose", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "-q", "-v", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, "-q can't be used with -v or -vv")
+	})
+}
+
+func TestColorMode(t *testing.T) {
+	fileTree := `
+		.git:
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+	`
+
+	t.Run("Valid", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "--color=never", "pc1")
+		c.RequireReturnCode(t, 0)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		c := testcase.RunCase(t, fileTree, "./run", "--color=sometimes", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, `unknown color mode "sometimes"`)
+	})
+}
@@ -0,0 +1,104 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/backdround/deploy-configs/tests/testcase"
+)
+
+func TestWhen(t *testing.T) {
+	t.Setenv("DEPLOY_CONFIGS_TEST_MACHINE", "laptop")
+
+	initialFileTree := `
+		.git:
+		configs:
+			link.conf:
+				type: file
+			template.conf:
+				type: file
+				data: "some data"
+		deploy-configs.yaml:
+			type: file
+			data: |
+				instances:
+					pc1:
+						links:
+							link1:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link1"
+								when: '{{eq (env "DEPLOY_CONFIGS_TEST_MACHINE") "laptop"}}'
+							link2:
+								target: "{{.GitRoot}}/configs/link.conf"
+								link: "{{.GitRoot}}/deploy/link2"
+								when: '{{which "deploy-configs-missing-program"}}'
+						templates:
+							template1:
+								input: "{{.GitRoot}}/configs/template.conf"
+								output: "{{.GitRoot}}/deploy/template1"
+								data:
+								when: '{{exists "./configs/missing.conf"}}'
+	`
+
+	t.Run("DisabledUnitsAreSkipped", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "-v", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireVerboseMessage(t, `"link2" link is skipped, because its condition is false`)
+		c.RequireVerboseMessage(t, `"template1" template is skipped`)
+		c.RequireFileTree(t, `
+			.git:
+			configs:
+				link.conf:
+					type: file
+				template.conf:
+					type: file
+			deploy:
+				link1:
+					type: link
+					path: ../configs/link.conf
+			deploy-configs.yaml:
+				type: file
+		`)
+	})
+
+	t.Run("DisabledUnitsAreSummarized", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireSuccessMessage(t, "links      1        0         1        0")
+		c.RequireSuccessMessage(t, "templates  0        0         1        0")
+
+		m := c.ReadManifest(t, "pc1")
+		require.Len(t, m.Links, 1)
+		require.Len(t, m.Templates, 0)
+	})
+
+	t.Run("DisabledUnitsAreEmitted", func(t *testing.T) {
+		c := testcase.RunCase(t, initialFileTree, "./run", "--output", "json",
+			"pc1")
+		c.RequireReturnCode(t, 0)
+		c.RequireLogMessage(t, `{"type":"unit","instance":"pc1","kind":"link",`+
+			`"name":"link2","action":"skipped",`+
+			`"reason":"condition is false","duration":0}`)
+		c.RequireLogMessage(t, `"kind":"template","name":"template1",`+
+			`"action":"skipped","reason":"condition is false"`)
+	})
+
+	t.Run("InvalidCondition", func(t *testing.T) {
+		fileTree := `
+			deploy-configs.yaml:
+				type: file
+				data: |
+					instances:
+						pc1:
+							links:
+								link1:
+									target: "./configs/link.conf"
+									link: "./deploy/link1"
+									when: "maybe"
+		`
+		c := testcase.RunCase(t, fileTree, "./run", "pc1")
+		c.RequireReturnCode(t, 1)
+		c.RequireFailMessage(t, `unable to evaluate "link1" link condition`)
+	})
+}